@@ -0,0 +1,325 @@
+// Package config replaces the compile-time constants of the lessons with
+// settings that can be changed without recompiling. A lesson describes its
+// settings as a struct holding the defaults; Load fills it from, lowest to
+// highest precedence, those defaults, a JSON or TOML config file, the
+// environment and the command line, all under the fields' json names.
+//
+// Fields tagged tunable:"true" are picked up again by Source.Reload when the
+// config file changes; the others need a restart.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables are the upper-cased json names with this prefix,
+// e.g. SDL_TUTORIAL_VSYNC=false
+const ENV_PREFIX = "SDL_TUTORIAL_"
+
+// Config file read unless -config names another one
+const DEFAULT_PATH = "config.json"
+
+/* ------------------------------ window ------------------------------ */
+
+// Settings every lesson with a window has; embed it in a lesson's settings
+type Window struct {
+	Title  string `json:"title"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	VSync  bool   `json:"vsync"`
+}
+
+func DefaultWindow() Window {
+	return Window{"test", 640, 480, true}
+}
+
+func (w *Window) Validate() error {
+	if w.Width <= 0 || w.Height <= 0 {
+		return fmt.Errorf("invalid screen size %dx%d", w.Width, w.Height)
+	}
+	return nil
+}
+
+// Flags for sdl.CreateRenderer
+func (w *Window) RendererFlags() uint32 {
+	var flags uint32 = sdl.RENDERER_ACCELERATED
+	if w.VSync {
+		flags |= sdl.RENDERER_PRESENTVSYNC
+	}
+	return flags
+}
+
+/* ------------------------------ loading ------------------------------ */
+
+// Settings implementing it are checked after every load
+type Validator interface {
+	Validate() error
+}
+
+// Everything needed to load settings, and to load them again on reload
+type Source struct {
+	path  string
+	flags map[string]string
+
+	// Copy of the settings as they were before the first load
+	defaults reflect.Value
+
+	// Modification time of the file when it was last read
+	modTime time.Time
+}
+
+// Parses the command line of the lesson and loads settings, a pointer to a
+// struct holding the defaults. Bad flags and -help exit like they do with
+// the flag package.
+func Load(settings any) (*Source, error) {
+	src, err := Parse(filepath.Base(os.Args[0]), settings, os.Args[1:])
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	} else if err != nil {
+		// The flag package has printed the error and usage already
+		os.Exit(2)
+	}
+	return src, src.Load(settings)
+}
+
+// Registers a flag per field of settings plus -config, and parses args.
+// settings is not changed; its current values are the defaults.
+func Parse(name string, settings any, args []string) (*Source, error) {
+	v := reflect.ValueOf(settings)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("settings must be a pointer to a struct, got %T", settings)
+	}
+
+	src := &Source{flags: map[string]string{}, defaults: reflect.New(v.Elem().Type()).Elem()}
+	src.defaults.Set(v.Elem())
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&src.path, "config", DEFAULT_PATH, "path of the config file, JSON or TOML")
+
+	var err error
+	fields(v, func(name string, field reflect.StructField, value reflect.Value) {
+		if !settable(value) {
+			err = fmt.Errorf("%s: unsupported type %s", name, value.Type())
+			return
+		}
+		usage := fmt.Sprintf("%s (default %v)", name, value.Interface())
+		if field.Tag.Get("tunable") == "true" {
+			usage += ", reloaded with the config file"
+		}
+		f := flagValue{name, src.flags}
+		if value.Kind() == reflect.Bool {
+			fs.Var(boolFlagValue{f}, name, usage)
+		} else {
+			fs.Var(f, name, usage)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// Merges defaults, file, environment and flags into settings
+func (src *Source) Load(settings any) error {
+	v := reflect.ValueOf(settings)
+	v.Elem().Set(src.defaults)
+
+	if info, err := os.Stat(src.path); err == nil {
+		src.modTime = info.ModTime()
+	}
+	if err := loadFile(v, src.path); err != nil {
+		return err
+	}
+	if err := loadEnv(v); err != nil {
+		return err
+	}
+	if err := src.applyFlags(v); err != nil {
+		return err
+	}
+	if validator, ok := settings.(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+// Reloads the tunable fields into settings if the config file changed since
+// it was last read. Reports whether anything was reloaded. On error
+// settings are unchanged.
+func (src *Source) Reload(settings any) (bool, error) {
+	info, err := os.Stat(src.path)
+	if err != nil || !info.ModTime().After(src.modTime) {
+		return false, nil
+	}
+
+	fresh := reflect.New(src.defaults.Type())
+	if err := src.Load(fresh.Interface()); err != nil {
+		return false, err
+	}
+
+	current := reflect.ValueOf(settings).Elem()
+	fields(fresh, func(name string, field reflect.StructField, value reflect.Value) {
+		if field.Tag.Get("tunable") == "true" {
+			current.FieldByIndex(field.Index).Set(value)
+		}
+	})
+	return true, nil
+}
+
+/* ------------------------------ fields ------------------------------ */
+
+// Calls f with the json name and the settable value of every field of the
+// struct settings points to. Fields of embedded structs count as the
+// struct's own, like they do for encoding/json; field.Index is relative to
+// the outer struct.
+func fields(settings reflect.Value, f func(name string, field reflect.StructField, value reflect.Value)) {
+	var walk func(v reflect.Value, index []int)
+	walk = func(v reflect.Value, index []int) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			field.Index = append(append([]int(nil), index...), i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), field.Index)
+				continue
+			}
+			name := field.Tag.Get("json")
+			if name == "" || name == "-" || !field.IsExported() {
+				continue
+			}
+			f(name, field, v.Field(i))
+		}
+	}
+	walk(settings.Elem(), nil)
+}
+
+func settable(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// Sets a field from its textual form, as used by flags and the environment
+func setField(value reflect.Value, text string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(text, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(x)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+/* ------------------------------ layers ------------------------------ */
+
+// A missing file is fine, the other layers still apply. Files ending in
+// .toml are read as TOML, all others as JSON. Either way unknown keys are
+// errors rather than ignored, so typos don't go unnoticed.
+func loadFile(settings reflect.Value, path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = loadTOML(settings, data)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(settings.Interface())
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+func loadEnv(settings reflect.Value) error {
+	var err error
+	fields(settings, func(name string, field reflect.StructField, value reflect.Value) {
+		text, ok := os.LookupEnv(ENV_PREFIX + strings.ToUpper(name))
+		if !ok || err != nil {
+			return
+		}
+		if e := setField(value, text); e != nil {
+			err = fmt.Errorf("%s%s: %v", ENV_PREFIX, strings.ToUpper(name), e)
+		}
+	})
+	return err
+}
+
+func (src *Source) applyFlags(settings reflect.Value) error {
+	var err error
+	fields(settings, func(name string, field reflect.StructField, value reflect.Value) {
+		text, ok := src.flags[name]
+		if !ok || err != nil {
+			return
+		}
+		if e := setField(value, text); e != nil {
+			err = fmt.Errorf("-%s: %v", name, e)
+		}
+	})
+	return err
+}
+
+/* ------------------------------ flags ------------------------------ */
+
+// Stores a flag's text until the settings are loaded
+type flagValue struct {
+	name   string
+	values map[string]string
+}
+
+func (f flagValue) String() string {
+	return f.values[f.name]
+}
+
+func (f flagValue) Set(text string) error {
+	f.values[f.name] = text
+	return nil
+}
+
+// Bool flags may be given without a value, like -vsync
+type boolFlagValue struct {
+	flagValue
+}
+
+func (boolFlagValue) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testSettings struct {
+	Window
+	Asset string  `json:"asset"`
+	Speed int     `json:"speed" tunable:"true"`
+	Scale float64 `json:"scale"`
+}
+
+func defaultTestSettings() testSettings {
+	return testSettings{DefaultWindow(), "assets/dot.bmp", 2, 1}
+}
+
+func writeFile(t *testing.T, name, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func load(t *testing.T, args ...string) (testSettings, *Source, error) {
+	t.Helper()
+	s := defaultTestSettings()
+	src, err := Parse("test", &s, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, src, src.Load(&s)
+}
+
+func TestParseTOMLLine(t *testing.T) {
+	for _, c := range []struct {
+		line, key, value string
+	}{
+		{"", "", ""},
+		{"   # a comment", "", ""},
+		{"width = 800", "width", "800"},
+		{"width=1_024 # wide", "width", "1_024"},
+		{"vsync = false\r", "vsync", "false"},
+		{`title = "Tab\there \"quoted\""`, "title", "\"Tab\there \"quoted\"\""},
+		{`title = "a # not a comment" # a comment`, "title", `"a # not a comment"`},
+		{`asset = 'C:\assets\dot.bmp'`, "asset", `'C:\assets\dot.bmp'`},
+	} {
+		key, value, err := parseTOMLLine(c.line)
+		if err != nil || key != c.key || value != c.value {
+			t.Errorf("%q: got %q, %q, %v, want %q, %q", c.line, key, value, err, c.key, c.value)
+		}
+	}
+
+	for _, line := range []string{
+		"[window]",
+		"width",
+		"= 800",
+		"width =",
+		"width = # nothing",
+		`title = "unterminated`,
+		`title = 'unterminated`,
+		`title = "bad \q escape"`,
+		`title = "a" b`,
+	} {
+		if _, _, err := parseTOMLLine(line); err == nil {
+			t.Errorf("%q parsed", line)
+		}
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	s := defaultTestSettings()
+	err := loadTOML(reflect.ValueOf(&s), []byte(`# Window
+title = "Test"
+width = 1_280
+vsync = false
+
+asset = 'assets/other.png'
+scale = 1.5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testSettings{Window{"Test", 1280, 480, false}, "assets/other.png", 2, 1.5}
+	if s != want {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+
+	for _, c := range []struct {
+		name, text string
+	}{
+		{"unknown key", "widht = 800"},
+		{"set twice", "width = 800\nwidth = 900"},
+		{"quoted number", `width = "800"`},
+		{"unquoted string", "title = Test"},
+		{"not a number", "width = wide"},
+		{"not a bool", "vsync = yes"},
+		{"bad line", "title = \"Test\"\n[window]"},
+	} {
+		s := defaultTestSettings()
+		if err := loadTOML(reflect.ValueOf(&s), []byte(c.text)); err == nil {
+			t.Errorf("%s: loaded", c.name)
+		}
+	}
+}
+
+// The same typo is an error in either format
+func TestUnknownKeys(t *testing.T) {
+	for _, path := range []string{
+		writeFile(t, "config.json", `{"title": "Test", "widht": 800}`),
+		writeFile(t, "config.toml", "title = \"Test\"\nwidht = 800"),
+	} {
+		if _, _, err := load(t, "-config", path); err == nil || !strings.Contains(err.Error(), "widht") {
+			t.Errorf("%s: got %v, want an error about widht", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestMissingFile(t *testing.T) {
+	s, _, err := load(t, "-config", filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != defaultTestSettings() {
+		t.Errorf("got %+v, want the defaults", s)
+	}
+}
+
+// Defaults < file < environment < flags
+func TestPrecedence(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+title = "From the file"
+width = 800
+height = 600
+speed = 3
+`)
+	t.Setenv(ENV_PREFIX+"HEIGHT", "700")
+	t.Setenv(ENV_PREFIX+"SPEED", "4")
+	t.Setenv(ENV_PREFIX+"VSYNC", "false")
+
+	s, _, err := load(t, "-config", path, "-speed", "5", "-vsync")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := defaultTestSettings()
+	want.Title = "From the file"
+	want.Width = 800
+	want.Height = 700
+	want.Speed = 5
+	want.VSync = true
+	if s != want {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if _, _, err := load(t, "-width", "0"); err == nil {
+		t.Error("zero width loaded")
+	}
+	if _, _, err := load(t, "-width", "wide"); err == nil {
+		t.Error("bad width loaded")
+	}
+	t.Setenv(ENV_PREFIX+"SCALE", "big")
+	if _, _, err := load(t); err == nil {
+		t.Error("bad environment variable loaded")
+	}
+}
+
+// Only tunable fields change on reload, and a broken file changes nothing
+func TestReload(t *testing.T) {
+	path := writeFile(t, "config.json", `{"title": "Before", "speed": 3}`)
+	s, src, err := load(t, "-config", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded, err := src.Reload(&s); reloaded || err != nil {
+		t.Errorf("unchanged file: reloaded %v, %v", reloaded, err)
+	}
+
+	touch := func(text string) {
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		later := src.modTime.Add(time.Second)
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	touch(`{"title": "After", "speed": 7}`)
+	if reloaded, err := src.Reload(&s); !reloaded || err != nil {
+		t.Fatalf("changed file: reloaded %v, %v", reloaded, err)
+	}
+	if s.Speed != 7 || s.Title != "Before" {
+		t.Errorf("got speed %d, title %q, want 7, Before", s.Speed, s.Title)
+	}
+
+	touch(`{"speed": 9, "width": -1}`)
+	if _, err := src.Reload(&s); err == nil {
+		t.Error("invalid file reloaded")
+	}
+	if s.Speed != 7 {
+		t.Errorf("speed %d after a failed reload, want 7", s.Speed)
+	}
+}
+
+func TestParseRejectsUnsupportedFields(t *testing.T) {
+	var s struct {
+		Sizes []int `json:"sizes"`
+	}
+	if _, err := Parse("test", &s, nil); err == nil {
+		t.Error("slice field accepted")
+	}
+	if _, err := Parse("test", s, nil); err == nil {
+		t.Error("struct value accepted")
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Reads the part of TOML a flat config needs: key = value lines with
+// strings, numbers and booleans, and comments. Keys are the json names.
+func loadTOML(settings reflect.Value, data []byte) error {
+	values := map[string]string{}
+	for lineNo, line := range strings.Split(string(data), "\n") {
+		key, value, err := parseTOMLLine(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", lineNo+1, err)
+		}
+		if key == "" {
+			continue
+		}
+		if _, ok := values[key]; ok {
+			return fmt.Errorf("line %d: %s is set twice", lineNo+1, key)
+		}
+		values[key] = value
+	}
+
+	var err error
+	fields(settings, func(name string, field reflect.StructField, value reflect.Value) {
+		text, ok := values[name]
+		if !ok || err != nil {
+			return
+		}
+		delete(values, name)
+
+		// Strings are quoted, other values aren't
+		quoted := strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'")
+		if quoted != (value.Kind() == reflect.String) {
+			err = fmt.Errorf("%s: wrong type of value %s", name, text)
+			return
+		}
+		if quoted {
+			text = text[1 : len(text)-1]
+		} else if value.CanInt() || value.CanFloat() {
+			// Underscores may separate digits
+			text = strings.ReplaceAll(text, "_", "")
+		}
+		if e := setField(value, text); e != nil {
+			err = fmt.Errorf("%s: %v", name, e)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Catch typos rather than ignore them
+	for key := range values {
+		return fmt.Errorf("unknown key %s", key)
+	}
+	return nil
+}
+
+// Splits a line into its key and its value, strings still quoted but with
+// escapes resolved. Blank and comment lines give an empty key.
+func parseTOMLLine(line string) (key, value string, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", nil
+	}
+	if strings.HasPrefix(line, "[") {
+		return "", "", fmt.Errorf("tables are not supported")
+	}
+
+	eq := strings.IndexByte(line, '=')
+	if eq < 0 {
+		return "", "", fmt.Errorf("expected key = value")
+	}
+	key = strings.TrimSpace(line[:eq])
+	rest := strings.TrimSpace(line[eq+1:])
+	if key == "" {
+		return "", "", fmt.Errorf("missing key")
+	}
+
+	switch {
+	case strings.HasPrefix(rest, `"`):
+		// Basic strings have the same escapes as Go's
+		end := 1
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(rest) {
+			return "", "", fmt.Errorf("unterminated string")
+		}
+		text, err := strconv.Unquote(rest[:end+1])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid string %s", rest[:end+1])
+		}
+		value, rest = `"`+text+`"`, rest[end+1:]
+	case strings.HasPrefix(rest, "'"):
+		// Literal strings have no escapes
+		end := strings.IndexByte(rest[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string")
+		}
+		value, rest = rest[:end+2], rest[end+2:]
+	default:
+		value, rest, _ = strings.Cut(rest, "#")
+		value = strings.TrimSpace(value)
+		rest = ""
+		if value == "" {
+			return "", "", fmt.Errorf("missing value")
+		}
+	}
+
+	// Only a comment may follow a string
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", "", fmt.Errorf("unexpected %s after the value", rest)
+	}
+	return key, value, nil
+}
@@ -3,12 +3,18 @@ package main
 import (
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
+	"golang-sdl-tutorials/config"
 )
 
-const (
-	SCREEN_WIDTH  = 640
-	SCREEN_HEIGHT = 480
-)
+// Screen size, asset paths and such, set from the defaults here, a config
+// file, the environment and the command line
+type settings struct {
+	config.Window
+	FooImage        string `json:"fooImage"`
+	BackgroundImage string `json:"backgroundImage"`
+}
+
+var gConfig = settings{config.DefaultWindow(), "assets/foo.png", "assets/background.png"}
 
 var gWindow *sdl.Window
 var gRenderer *sdl.Renderer
@@ -78,21 +84,21 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create renderer
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
 
 	return window, renderer, nil
 }
 
 func loadMedia() {
-	fooLTexture = NewLTexture(gRenderer, "assets/foo.png", &rgb{0, 255, 255})
-	bgLTexture = NewLTexture(gRenderer, "assets/background.png", nil)
+	fooLTexture = NewLTexture(gRenderer, gConfig.FooImage, &rgb{0, 255, 255})
+	bgLTexture = NewLTexture(gRenderer, gConfig.BackgroundImage, nil)
 }
 
 func close() {
@@ -114,7 +120,8 @@ func must(err error) {
 }
 
 func main() {
-	var err error
+	_, err := config.Load(&gConfig)
+	must(err)
 
 	gWindow, gRenderer, err = initSDL()
 	must(err)
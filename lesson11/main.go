@@ -3,12 +3,17 @@ package main
 import (
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
+	"golang-sdl-tutorials/config"
 )
 
-const (
-	SCREEN_WIDTH  = 640
-	SCREEN_HEIGHT = 480
-)
+// Screen size, asset paths and such, set from the defaults here, a config
+// file, the environment and the command line
+type settings struct {
+	config.Window
+	DotsImage string `json:"dotsImage"`
+}
+
+var gConfig = settings{config.DefaultWindow(), "assets/dots.png"}
 
 var gWindow *sdl.Window
 var gRenderer *sdl.Renderer
@@ -82,20 +87,20 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create renderer
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
 
 	return window, renderer, nil
 }
 
 func loadMedia() {
-	gSpriteSheetLTexture = NewLTexture(gRenderer, "assets/dots.png", &rgb{0, 255, 255})
+	gSpriteSheetLTexture = NewLTexture(gRenderer, gConfig.DotsImage, &rgb{0, 255, 255})
 
 	// Set top left sprite
 	gSpriteClips[0] = &sdl.Rect{0, 0, 100, 100}
@@ -128,7 +133,8 @@ func must(err error) {
 }
 
 func main() {
-	var err error
+	_, err := config.Load(&gConfig)
+	must(err)
 
 	gWindow, gRenderer, err = initSDL()
 	must(err)
@@ -153,13 +159,13 @@ func main() {
 		gSpriteSheetLTexture.render(0, 0, gSpriteClips[0])
 
 		//Render top right sprite
-		gSpriteSheetLTexture.render(SCREEN_WIDTH-gSpriteClips[1].W, 0, gSpriteClips[1])
+		gSpriteSheetLTexture.render(int32(gConfig.Width)-gSpriteClips[1].W, 0, gSpriteClips[1])
 
 		//Render bottom left sprite
-		gSpriteSheetLTexture.render(0, SCREEN_HEIGHT-gSpriteClips[2].H, gSpriteClips[2])
+		gSpriteSheetLTexture.render(0, int32(gConfig.Height)-gSpriteClips[2].H, gSpriteClips[2])
 
 		//Render bottom right sprite
-		gSpriteSheetLTexture.render(SCREEN_WIDTH-gSpriteClips[3].W, SCREEN_HEIGHT-gSpriteClips[3].H, gSpriteClips[3])
+		gSpriteSheetLTexture.render(int32(gConfig.Width)-gSpriteClips[3].W, int32(gConfig.Height)-gSpriteClips[3].H, gSpriteClips[3])
 
 		// Update screen
 		gRenderer.Present()
@@ -3,12 +3,17 @@ package main
 import (
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
+	"golang-sdl-tutorials/config"
 )
 
-const (
-	SCREEN_WIDTH  = 640
-	SCREEN_HEIGHT = 480
-)
+// Screen size, asset paths and such, set from the defaults here, a config
+// file, the environment and the command line
+type settings struct {
+	config.Window
+	ColorsImage string `json:"colorsImage"`
+}
+
+var gConfig = settings{config.DefaultWindow(), "assets/colors.png"}
 
 var gWindow *sdl.Window
 var gRenderer *sdl.Renderer
@@ -83,20 +88,20 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create renderer
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
 
 	return window, renderer, nil
 }
 
 func loadMedia() {
-	gLTexture = NewLTexture(gRenderer, "assets/colors.png", nil)
+	gLTexture = NewLTexture(gRenderer, gConfig.ColorsImage, nil)
 }
 
 func close() {
@@ -117,7 +122,8 @@ func must(err error) {
 }
 
 func main() {
-	var err error
+	_, err := config.Load(&gConfig)
+	must(err)
 
 	gWindow, gRenderer, err = initSDL()
 	must(err)
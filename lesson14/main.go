@@ -3,12 +3,17 @@ package main
 import (
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
+	"golang-sdl-tutorials/config"
 )
 
-const (
-	SCREEN_WIDTH  = 640
-	SCREEN_HEIGHT = 480
-)
+// Screen size, asset paths and such, set from the defaults here, a config
+// file, the environment and the command line
+type settings struct {
+	config.Window
+	FooImage string `json:"fooImage"`
+}
+
+var gConfig = settings{config.DefaultWindow(), "assets/foo.png"}
 
 var gWindow *sdl.Window
 var gRenderer *sdl.Renderer
@@ -96,20 +101,20 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create renderer
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
 
 	return window, renderer, nil
 }
 
 func loadMedia() {
-	gSpriteSheetTexture = NewLTexture(gRenderer, "assets/foo.png", &rgb{0, 255, 255})
+	gSpriteSheetTexture = NewLTexture(gRenderer, gConfig.FooImage, &rgb{0, 255, 255})
 
 	gSpriteClips[0] = &sdl.Rect{0, 0, 64, 205}
 	gSpriteClips[1] = &sdl.Rect{64, 0, 64, 205}
@@ -135,7 +140,8 @@ func must(err error) {
 }
 
 func main() {
-	var err error
+	_, err := config.Load(&gConfig)
+	must(err)
 
 	gWindow, gRenderer, err = initSDL()
 	must(err)
@@ -159,7 +165,7 @@ func main() {
 
 		// Render current frame
 		currentClip := gSpriteClips[frame/4]
-		gSpriteSheetTexture.render((SCREEN_WIDTH-currentClip.W)/2, (SCREEN_HEIGHT-currentClip.H)/2, currentClip)
+		gSpriteSheetTexture.render((int32(gConfig.Width)-currentClip.W)/2, (int32(gConfig.Height)-currentClip.H)/2, currentClip)
 
 		frame++
 		if frame/4 >= WALKING_ANIMATION_FRAMES {
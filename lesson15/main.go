@@ -3,12 +3,17 @@ package main
 import (
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
+	"golang-sdl-tutorials/config"
 )
 
-const (
-	SCREEN_WIDTH  = 640
-	SCREEN_HEIGHT = 480
-)
+// Screen size, asset paths and such, set from the defaults here, a config
+// file, the environment and the command line
+type settings struct {
+	config.Window
+	ArrowImage string `json:"arrowImage"`
+}
+
+var gConfig = settings{config.DefaultWindow(), "assets/arrow.png"}
 
 var gWindow *sdl.Window
 var gRenderer *sdl.Renderer
@@ -104,20 +109,20 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create renderer
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
 
 	return window, renderer, nil
 }
 
 func loadMedia() {
-	gArrowTexture = NewLTexture(gRenderer, "assets/arrow.png", nil)
+	gArrowTexture = NewLTexture(gRenderer, gConfig.ArrowImage, nil)
 }
 
 func close() {
@@ -138,7 +143,8 @@ func must(err error) {
 }
 
 func main() {
-	var err error
+	_, err := config.Load(&gConfig)
+	must(err)
 
 	gWindow, gRenderer, err = initSDL()
 	must(err)
@@ -180,7 +186,7 @@ func main() {
 		gRenderer.Clear()
 
 		// Render arrow
-		gArrowTexture.renderRotationFlip((SCREEN_WIDTH-gArrowTexture.width)/2, (SCREEN_HEIGHT-gArrowTexture.height)/2, nil, degrees, nil, flipType)
+		gArrowTexture.renderRotationFlip((int32(gConfig.Width)-gArrowTexture.width)/2, (int32(gConfig.Height)-gArrowTexture.height)/2, nil, degrees, nil, flipType)
 
 		// Update screen
 		gRenderer.Present()
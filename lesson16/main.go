@@ -4,12 +4,17 @@ import (
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
 	"github.com/veandco/go-sdl2/sdl_ttf"
+	"golang-sdl-tutorials/config"
 )
 
-const (
-	SCREEN_WIDTH  = 640
-	SCREEN_HEIGHT = 480
-)
+// Screen size, asset paths and such, set from the defaults here, a config
+// file, the environment and the command line
+type settings struct {
+	config.Window
+	Font string `json:"font"`
+}
+
+var gConfig = settings{config.DefaultWindow(), "assets/lazy.ttf"}
 
 var gWindow *sdl.Window
 var gRenderer *sdl.Renderer
@@ -74,6 +79,9 @@ func (t *lTexture) loadFromFile(path string, colorKey *rgb) {
 }
 
 func (t *lTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
 	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
 	must(err)
 
@@ -123,14 +131,14 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create renderer
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
 
 	// Init font system
 	ttf.Init()
@@ -140,7 +148,7 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 
 func loadMedia() {
 	var err error
-	gFont, err = ttf.OpenFont("assets/lazy.ttf", 28)
+	gFont, err = ttf.OpenFont(gConfig.Font, 28)
 	must(err)
 
 	gTextTexture = NewTextLTexture(gRenderer, "The quick brown fox jumps over the lazy dog", gFont, sdl.Color{R: 0, G: 0, B: 0})
@@ -167,7 +175,8 @@ func must(err error) {
 }
 
 func main() {
-	var err error
+	_, err := config.Load(&gConfig)
+	must(err)
 
 	gWindow, gRenderer, err = initSDL()
 	must(err)
@@ -190,7 +199,7 @@ func main() {
 		gRenderer.Clear()
 
 		// Render text
-		gTextTexture.render((SCREEN_WIDTH-gTextTexture.width)/2, (SCREEN_HEIGHT-gTextTexture.height)/2, nil)
+		gTextTexture.render((int32(gConfig.Width)-gTextTexture.width)/2, (int32(gConfig.Height)-gTextTexture.height)/2, nil)
 
 		// Update screen
 		gRenderer.Present()
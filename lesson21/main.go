@@ -86,6 +86,9 @@ func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
 }
 
 func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
 	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
 	must(err)
 
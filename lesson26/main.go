@@ -1,28 +1,26 @@
 package main
 
 import (
+	"fmt"
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
 	"github.com/veandco/go-sdl2/sdl_ttf"
+	"golang-sdl-tutorials/config"
 )
 
 /* ------------------------------ global constants ------------------------------ */
 
-const (
-	SCREEN_WIDTH  = 640
-	SCREEN_HEIGHT = 480
-)
-
 const (
 	DOT_WIDTH  = 20
 	DOT_HEIGHT = 20
 )
 
-// Maximum axis velocity of the dot
-const DOT_VEL = 2
-
 /* ------------------------------ global variables ------------------------------ */
 
+// Screen size, asset paths and such, set from the defaults here, a config
+// file, the environment and the command line
+var gConfig = settings{config.DefaultWindow(), "assets/dot.bmp", 2}
+
 var gWindow *sdl.Window
 var gRenderer *sdl.Renderer
 
@@ -30,6 +28,24 @@ var gDotTexture *MyTexture
 
 /* ------------------------------ lesson-specific types ------------------------------ */
 
+type settings struct {
+	config.Window
+	DotImage string `json:"dotImage"`
+
+	// Maximum axis velocity of the dot
+	DotVel int `json:"dotVel"`
+}
+
+func (s *settings) Validate() error {
+	if err := s.Window.Validate(); err != nil {
+		return err
+	}
+	if s.DotVel < 0 {
+		return fmt.Errorf("dotVel must not be negative, got %d", s.DotVel)
+	}
+	return nil
+}
+
 type dot struct {
 	x, y       int32
 	velX, velY int32
@@ -46,13 +62,13 @@ func (d *dot) handleEvent(e sdl.Event) {
 		if t.Repeat == 0 {
 			switch t.Keysym.Scancode {
 			case sdl.SCANCODE_UP:
-				d.velY -= DOT_VEL
+				d.velY -= int32(gConfig.DotVel)
 			case sdl.SCANCODE_DOWN:
-				d.velY += DOT_VEL
+				d.velY += int32(gConfig.DotVel)
 			case sdl.SCANCODE_LEFT:
-				d.velX -= DOT_VEL
+				d.velX -= int32(gConfig.DotVel)
 			case sdl.SCANCODE_RIGHT:
-				d.velX += DOT_VEL
+				d.velX += int32(gConfig.DotVel)
 			}
 		}
 	}
@@ -63,7 +79,7 @@ func (d *dot) move() {
 	d.x += d.velX
 
 	// If the dot went too far to the left or right
-	if d.x < 0 || d.x+DOT_WIDTH > SCREEN_WIDTH {
+	if d.x < 0 || d.x+DOT_WIDTH > int32(gConfig.Width) {
 		d.x -= d.velX
 	}
 
@@ -71,7 +87,7 @@ func (d *dot) move() {
 	d.y += d.velY
 
 	// If the dot went too far to the up or down
-	if d.y < 0 || d.y+DOT_HEIGHT > SCREEN_HEIGHT {
+	if d.y < 0 || d.y+DOT_HEIGHT > int32(gConfig.Height) {
 		d.y -= d.velY
 	}
 }
@@ -188,14 +204,14 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create renderer
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
 
 	// Init font system
 	err = ttf.Init()
@@ -207,7 +223,7 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 }
 
 func loadMedia() {
-	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+	gDotTexture = NewMyTexture(gRenderer, gConfig.DotImage, nil)
 }
 
 func close() {
@@ -231,7 +247,8 @@ func must(err error) {
 /* ------------------------------ main ------------------------------ */
 
 func main() {
-	var err error
+	_, err := config.Load(&gConfig)
+	must(err)
 
 	gWindow, gRenderer, err = initSDL()
 	must(err)
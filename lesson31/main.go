@@ -0,0 +1,434 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"math"
+	"os"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+// The dimensions of the level
+const (
+	LEVEL_WIDTH  = 1920
+	LEVEL_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 4
+
+// Layer definitions of the background
+const BACKGROUND_CONFIG = "assets/layers.json"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+
+var gBackground *background
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y       int32
+	velX, velY int32
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX -= DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX += DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+func (d *dot) move() {
+	// Move the dot left or right, but keep it inside the level
+	d.x += d.velX
+	if d.x < 0 || d.x+DOT_WIDTH > LEVEL_WIDTH {
+		d.x -= d.velX
+	}
+
+	// Move the dot up or down, but keep it inside the level
+	d.y += d.velY
+	if d.y < 0 || d.y+DOT_HEIGHT > LEVEL_HEIGHT {
+		d.y -= d.velY
+	}
+}
+
+// Render the dot relative to the camera
+func (d *dot) render(camera *sdl.Rect) {
+	gDotTexture.render(d.x-camera.X, d.y-camera.Y, nil)
+}
+
+// Center the camera over the dot and keep it inside the level
+func (d *dot) updateCamera(camera *sdl.Rect) {
+	camera.X = (d.x + DOT_WIDTH/2) - SCREEN_WIDTH/2
+	camera.Y = (d.y + DOT_HEIGHT/2) - SCREEN_HEIGHT/2
+
+	if camera.X < 0 {
+		camera.X = 0
+	}
+	if camera.Y < 0 {
+		camera.Y = 0
+	}
+	if camera.X > LEVEL_WIDTH-camera.W {
+		camera.X = LEVEL_WIDTH - camera.W
+	}
+	if camera.Y > LEVEL_HEIGHT-camera.H {
+		camera.Y = LEVEL_HEIGHT - camera.H
+	}
+}
+
+// A single background layer as described in the config file.
+//
+// The scroll factors say how fast the layer follows the camera: 0 keeps it
+// fixed to the screen, 1 moves it together with the level, anything in
+// between makes it look farther away. The velocity is an extra auto-scroll
+// in pixels per frame, e.g. for drifting clouds.
+type layerConfig struct {
+	Image   string  `json:"image"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	ScrollX float64 `json:"scrollX"`
+	ScrollY float64 `json:"scrollY"`
+	TileX   bool    `json:"tileX"`
+	TileY   bool    `json:"tileY"`
+	VelX    float64 `json:"velX"`
+	VelY    float64 `json:"velY"`
+}
+
+type backgroundConfig struct {
+	// Layers from back to front
+	Layers []layerConfig `json:"layers"`
+}
+
+type bgLayer struct {
+	layerConfig
+
+	texture *MyTexture
+
+	// Offset accumulated by auto-scrolling
+	offsetX, offsetY float64
+}
+
+func (l *bgLayer) update() {
+	l.offsetX += l.VelX
+	l.offsetY += l.VelY
+
+	// Tiled layers repeat anyway, so keep the offset small
+	if l.TileX {
+		l.offsetX = math.Mod(l.offsetX, float64(l.texture.width))
+	}
+	if l.TileY {
+		l.offsetY = math.Mod(l.offsetY, float64(l.texture.height))
+	}
+}
+
+func (l *bgLayer) render(camera *sdl.Rect) {
+	w := float64(l.texture.width)
+	h := float64(l.texture.height)
+
+	// Screen position of the layer origin
+	x := l.X + l.offsetX - float64(camera.X)*l.ScrollX
+	y := l.Y + l.offsetY - float64(camera.Y)*l.ScrollY
+
+	// For tiled axes start with the tile covering the screen edge
+	if l.TileX {
+		x = math.Mod(x, w)
+		if x > 0 {
+			x -= w
+		}
+	}
+	if l.TileY {
+		y = math.Mod(y, h)
+		if y > 0 {
+			y -= h
+		}
+	}
+
+	for ty := y; ty < SCREEN_HEIGHT; ty += h {
+		for tx := x; tx < SCREEN_WIDTH; tx += w {
+			l.texture.render(int32(math.Floor(tx)), int32(math.Floor(ty)), nil)
+			if !l.TileX {
+				break
+			}
+		}
+		if !l.TileY {
+			break
+		}
+	}
+}
+
+type background struct {
+	layers []*bgLayer
+}
+
+func loadBackground(renderer *sdl.Renderer, path string) (*background, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config backgroundConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	b := &background{}
+	for _, lc := range config.Layers {
+		texture := &MyTexture{renderer: renderer}
+		if err := texture.reloadFromFile(lc.Image, nil); err != nil {
+			b.free()
+			return nil, err
+		}
+		b.layers = append(b.layers, &bgLayer{layerConfig: lc, texture: texture})
+	}
+	return b, nil
+}
+
+func (b *background) update() {
+	for _, l := range b.layers {
+		l.update()
+	}
+}
+
+func (b *background) render(camera *sdl.Rect) {
+	for _, l := range b.layers {
+		l.render(camera)
+	}
+}
+
+func (b *background) free() {
+	for _, l := range b.layers {
+		l.texture.free()
+	}
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	must(t.reloadFromFile(path, colorKey))
+}
+
+// Like loadFromFile, but returns the error and keeps the current texture if
+// the file can't be loaded
+func (t *MyTexture) reloadFromFile(path string, colorKey *sdl.Color) error {
+	surface, err := img.Load(path)
+	if err != nil {
+		return err
+	}
+	defer surface.Free()
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	texture, err := t.renderer.CreateTextureFromSurface(surface)
+	if err != nil {
+		return err
+	}
+
+	// Free pre-existing texture
+	t.free()
+	t.texture = texture
+	t.width = surface.W
+	t.height = surface.H
+	return nil
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+
+	var err error
+	gBackground, err = loadBackground(gRenderer, BACKGROUND_CONFIG)
+	must(err)
+}
+
+func close() {
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	gDotTexture.free()
+	gBackground.free()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var d dot
+
+	// The camera area
+	camera := &sdl.Rect{0, 0, SCREEN_WIDTH, SCREEN_HEIGHT}
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				// Reload the layer definitions; while tuning them a typo
+				// keeps the layers as they were
+				if t.Keysym.Scancode == sdl.SCANCODE_R {
+					bg, err := loadBackground(gRenderer, BACKGROUND_CONFIG)
+					if err != nil {
+						fmt.Fprintln(os.Stderr, "layers not reloaded:", err)
+						break
+					}
+					gBackground.free()
+					gBackground = bg
+				}
+			}
+
+			d.handleEvent(event)
+		}
+
+		// Move the dot and the camera following it
+		d.move()
+		d.updateCamera(camera)
+
+		// Scroll the background
+		gBackground.update()
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// Render background layers, then the dot
+		gBackground.render(camera)
+		d.render(camera)
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
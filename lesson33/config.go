@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"golang-sdl-tutorials/config"
+	"strconv"
+	"strings"
+)
+
+// Settings of the lesson, loaded by the config package. Fields tagged
+// tunable are picked up again when the config file changes.
+type settings struct {
+	config.Window
+	DotImage string `json:"dotImage"`
+
+	DotVel     int    `json:"dotVel" tunable:"true"`
+	Background string `json:"background" tunable:"true"`
+}
+
+func defaultSettings() settings {
+	return settings{config.DefaultWindow(), "assets/dot.bmp", 2, "ffffff"}
+}
+
+// Parses Background, given as hex RRGGBB
+func (s *settings) backgroundColor() (r, g, b uint8, err error) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(s.Background, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(s.Background, "#")) != 6 {
+		return 0, 0, 0, fmt.Errorf("background: %q is not a RRGGBB color", s.Background)
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), nil
+}
+
+func (s *settings) Validate() error {
+	if err := s.Window.Validate(); err != nil {
+		return err
+	}
+	if s.DotVel < 0 {
+		return fmt.Errorf("dotVel must not be negative, got %d", s.DotVel)
+	}
+	_, _, _, err := s.backgroundColor()
+	return err
+}
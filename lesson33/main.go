@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"golang-sdl-tutorials/config"
+	"os"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// How often the config file is checked for changes
+const RELOAD_INTERVAL_MS = 500
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+
+// Replaces the compile-time constants of the other lessons
+var gConfig = defaultSettings()
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y int32
+
+	// Direction the dot is moving in, -1, 0 or 1 per axis. The speed comes
+	// from the config, as it may change while a key is held.
+	dirX, dirY int32
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.dirY--
+			case sdl.SCANCODE_DOWN:
+				d.dirY++
+			case sdl.SCANCODE_LEFT:
+				d.dirX--
+			case sdl.SCANCODE_RIGHT:
+				d.dirX++
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.dirY++
+			case sdl.SCANCODE_DOWN:
+				d.dirY--
+			case sdl.SCANCODE_LEFT:
+				d.dirX++
+			case sdl.SCANCODE_RIGHT:
+				d.dirX--
+			}
+		}
+	}
+}
+
+func (d *dot) move() {
+	velX := d.dirX * int32(gConfig.DotVel)
+	velY := d.dirY * int32(gConfig.DotVel)
+
+	// Move the dot left or right
+	d.x += velX
+
+	// If the dot went too far to the left or right
+	if d.x < 0 || d.x+DOT_WIDTH > int32(gConfig.Width) {
+		d.x -= velX
+	}
+
+	// Move the dot up or down
+	d.y += velY
+
+	// If the dot went too far to the up or down
+	if d.y < 0 || d.y+DOT_HEIGHT > int32(gConfig.Height) {
+		d.y -= velY
+	}
+}
+
+func (d *dot) render() {
+	gDotTexture.render(d.x, d.y, nil)
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, gConfig.DotImage, nil)
+}
+
+func close() {
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	gDotTexture.free()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	configSource, err := config.Load(&gConfig)
+	must(err)
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var d dot
+
+	lastReload := sdl.GetTicks()
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			}
+
+			d.handleEvent(event)
+		}
+
+		// Pick up tunables edited in the config file; a broken file is
+		// reported and the old values stay in use
+		if sdl.GetTicks()-lastReload >= RELOAD_INTERVAL_MS {
+			lastReload = sdl.GetTicks()
+			reloaded, err := configSource.Reload(&gConfig)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "config not reloaded:", err)
+			} else if reloaded {
+				fmt.Printf("config reloaded: dotVel=%d background=%s\n", gConfig.DotVel, gConfig.Background)
+			}
+		}
+
+		// Move the dot
+		d.move()
+
+		// Clear screen
+		r, g, b, _ := gConfig.backgroundColor()
+		gRenderer.SetDrawColor(r, g, b, 255)
+		gRenderer.Clear()
+
+		// Render the dot
+		d.render()
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+// Virtual resolution the game is drawn in, whatever the window size is
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 3
+
+const WINDOW_TITLE = "SDL Tutorial"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *myWindow
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y       int32
+	velX, velY int32
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX -= DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX += DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+func (d *dot) move() {
+	// Move the dot left or right
+	d.x += d.velX
+
+	// If the dot went too far to the left or right
+	if d.x < 0 || d.x+DOT_WIDTH > SCREEN_WIDTH {
+		d.x -= d.velX
+	}
+
+	// Move the dot up or down
+	d.y += d.velY
+
+	// If the dot went too far to the up or down
+	if d.y < 0 || d.y+DOT_HEIGHT > SCREEN_HEIGHT {
+		d.y -= d.velY
+	}
+}
+
+func (d *dot) render() {
+	gDotTexture.render(d.x, d.y, nil)
+}
+
+/* ------------------------------ myWindow ------------------------------ */
+
+type myWindow struct {
+	window   *sdl.Window
+	renderer *sdl.Renderer
+
+	// Window size in screen coordinates
+	width  int32
+	height int32
+
+	// Window state
+	mouseFocus    bool
+	keyboardFocus bool
+	fullscreen    bool
+	minimized     bool
+
+	// sdl.WINDOW_FULLSCREEN or sdl.WINDOW_FULLSCREEN_DESKTOP
+	fullscreenMode uint32
+
+	// Scale the virtual resolution by whole numbers only
+	integerScale bool
+}
+
+// Creates a resizable window and its renderer. The renderer draws in a
+// fixed logical size and scales it to the window, letterboxing as needed.
+func NewMyWindow(title string, width, height int32) (*myWindow, error) {
+	window, err := sdl.CreateWindow(title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		int(width), int(height), sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
+	if err != nil {
+		return nil, err
+	}
+
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		window.Destroy()
+		return nil, err
+	}
+
+	if err := renderer.SetLogicalSize(SCREEN_WIDTH, SCREEN_HEIGHT); err != nil {
+		renderer.Destroy()
+		window.Destroy()
+		return nil, err
+	}
+
+	w := &myWindow{
+		window:         window,
+		renderer:       renderer,
+		width:          width,
+		height:         height,
+		mouseFocus:     true,
+		keyboardFocus:  true,
+		fullscreenMode: sdl.WINDOW_FULLSCREEN_DESKTOP,
+	}
+	w.updateTitle()
+	return w, nil
+}
+
+func (w *myWindow) free() {
+	w.renderer.Destroy()
+	w.window.Destroy()
+}
+
+func (w *myWindow) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.WindowEvent:
+		switch t.Event {
+		case sdl.WINDOWEVENT_SIZE_CHANGED:
+			w.width = t.Data1
+			w.height = t.Data2
+			w.renderer.Present()
+		case sdl.WINDOWEVENT_EXPOSED:
+			// Repaint on exposure
+			w.renderer.Present()
+		case sdl.WINDOWEVENT_ENTER:
+			w.mouseFocus = true
+		case sdl.WINDOWEVENT_LEAVE:
+			w.mouseFocus = false
+		case sdl.WINDOWEVENT_FOCUS_GAINED:
+			w.keyboardFocus = true
+		case sdl.WINDOWEVENT_FOCUS_LOST:
+			w.keyboardFocus = false
+		case sdl.WINDOWEVENT_MINIMIZED:
+			w.minimized = true
+		case sdl.WINDOWEVENT_MAXIMIZED, sdl.WINDOWEVENT_RESTORED:
+			w.minimized = false
+		}
+		w.updateTitle()
+
+	case *sdl.KeyDownEvent:
+		if t.Repeat != 0 {
+			break
+		}
+		switch t.Keysym.Scancode {
+		case sdl.SCANCODE_RETURN:
+			// Borderless fullscreen at the desktop resolution
+			w.toggleFullscreen(sdl.WINDOW_FULLSCREEN_DESKTOP)
+		case sdl.SCANCODE_F11:
+			// Exclusive fullscreen, changing the display mode
+			w.toggleFullscreen(sdl.WINDOW_FULLSCREEN)
+		case sdl.SCANCODE_I:
+			w.setIntegerScale(!w.integerScale)
+		}
+	}
+}
+
+// Switches to the given fullscreen mode, or back to windowed if already in it
+func (w *myWindow) toggleFullscreen(mode uint32) {
+	if w.fullscreen && w.fullscreenMode == mode {
+		must(w.window.SetFullscreen(0))
+		w.fullscreen = false
+	} else {
+		must(w.window.SetFullscreen(mode))
+		w.fullscreen = true
+		w.fullscreenMode = mode
+		w.minimized = false
+	}
+	w.updateTitle()
+}
+
+func (w *myWindow) setIntegerScale(enabled bool) {
+	must(w.renderer.SetIntegerScale(enabled))
+	w.integerScale = enabled
+	w.updateTitle()
+}
+
+// Maps a point in window coordinates, like the ones from GetMouseState,
+// to the virtual resolution. Mouse events are mapped by SDL already.
+func (w *myWindow) toLogical(x, y int) (int32, int32) {
+	// Account for high DPI displays where pixels and screen coordinates differ
+	outputWidth, outputHeight, err := w.renderer.GetOutputSize()
+	must(err)
+	windowWidth, windowHeight := w.window.GetSize()
+	px := float32(x) * float32(outputWidth) / float32(windowWidth)
+	py := float32(y) * float32(outputHeight) / float32(windowHeight)
+
+	// Undo the scaling, then the letterbox offset
+	var viewport sdl.Rect
+	w.renderer.GetViewport(&viewport)
+	scaleX, scaleY := w.renderer.GetScale()
+	return int32(px/scaleX) - viewport.X, int32(py/scaleY) - viewport.Y
+}
+
+func (w *myWindow) updateTitle() {
+	title := WINDOW_TITLE + " - " + onOff("MouseFocus", w.mouseFocus) + " " +
+		onOff("KeyboardFocus", w.keyboardFocus) + " " + onOff("IntegerScale", w.integerScale)
+	if w.fullscreen && w.fullscreenMode == sdl.WINDOW_FULLSCREEN {
+		title += " Fullscreen"
+	} else if w.fullscreen {
+		title += " DesktopFullscreen"
+	}
+	w.window.SetTitle(title)
+}
+
+func onOff(name string, on bool) string {
+	if on {
+		return name + ":On"
+	}
+	return name + ":Off"
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*myWindow, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Keep pixel art crisp when scaling up
+	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "0")
+
+	// Create window and renderer
+	window, err := NewMyWindow(WINDOW_TITLE, SCREEN_WIDTH, SCREEN_HEIGHT)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, window.renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+}
+
+func close() {
+	gDotTexture.free()
+
+	gWindow.free()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var d dot
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			}
+
+			gWindow.handleEvent(event)
+			d.handleEvent(event)
+		}
+
+		// Nothing to see while minimized
+		if gWindow.minimized {
+			sdl.Delay(16)
+			continue
+		}
+
+		// Move the dot
+		d.move()
+
+		// Clear screen, including the letterbox bars
+		gRenderer.SetDrawColor(0, 0, 0, 255)
+		gRenderer.Clear()
+
+		// Fill the virtual screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.FillRect(&sdl.Rect{0, 0, SCREEN_WIDTH, SCREEN_HEIGHT})
+
+		// Render the dot
+		d.render()
+
+		// Render a crosshair at the mouse, in virtual coordinates
+		mouseX, mouseY, _ := sdl.GetMouseState()
+		x, y := gWindow.toLogical(mouseX, mouseY)
+		gRenderer.SetDrawColor(255, 0, 0, 255)
+		gRenderer.DrawLine(int(x)-8, int(y), int(x)+8, int(y))
+		gRenderer.DrawLine(int(x), int(y)-8, int(x), int(y)+8)
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
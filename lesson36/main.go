@@ -0,0 +1,542 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+// Size of the inspector and preview windows
+const (
+	TOOL_WIDTH  = 320
+	TOOL_HEIGHT = 240
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 3
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindows *windowRegistry
+
+var gGameWindow *myWindow
+var gInspectorWindow *myWindow
+var gPreviewWindow *myWindow
+
+var gFont *ttf.Font
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y       int32
+	velX, velY int32
+
+	// Loaded into the game window
+	texture *MyTexture
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX -= DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX += DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+func (d *dot) move() {
+	// Move the dot left or right
+	d.x += d.velX
+
+	// If the dot went too far to the left or right
+	if d.x < 0 || d.x+DOT_WIDTH > SCREEN_WIDTH {
+		d.x -= d.velX
+	}
+
+	// Move the dot up or down
+	d.y += d.velY
+
+	// If the dot went too far to the up or down
+	if d.y < 0 || d.y+DOT_HEIGHT > SCREEN_HEIGHT {
+		d.y -= d.velY
+	}
+}
+
+func (d *dot) render(w *myWindow) {
+	w.draw(d.texture, d.x, d.y, nil)
+}
+
+/* ------------------------------ myWindow ------------------------------ */
+
+// A window with its own renderer. Textures are created through the window,
+// as a texture only works with the renderer that created it.
+type myWindow struct {
+	window   *sdl.Window
+	renderer *sdl.Renderer
+	id       uint32
+
+	// Window size
+	width  int32
+	height int32
+
+	// Window state
+	shown         bool
+	mouseFocus    bool
+	keyboardFocus bool
+	minimized     bool
+
+	// Textures owned by this window's renderer
+	textures []*MyTexture
+
+	// Called with the window's events, after the window itself handled them
+	onEvent func(e sdl.Event)
+}
+
+func NewMyWindow(title string, width, height int32) (*myWindow, error) {
+	window, err := sdl.CreateWindow(title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		int(width), int(height), sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
+	if err != nil {
+		return nil, err
+	}
+
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		window.Destroy()
+		return nil, err
+	}
+
+	return &myWindow{
+		window:        window,
+		renderer:      renderer,
+		id:            window.GetID(),
+		width:         width,
+		height:        height,
+		shown:         true,
+		mouseFocus:    true,
+		keyboardFocus: true,
+	}, nil
+}
+
+// Frees the window's textures, then the renderer and the window
+func (w *myWindow) free() {
+	for _, t := range w.textures {
+		t.free()
+	}
+	w.textures = nil
+
+	w.renderer.Destroy()
+	w.window.Destroy()
+}
+
+// Loads an image into a texture owned by this window
+func (w *myWindow) loadTexture(path string, colorKey *sdl.Color) *MyTexture {
+	t := NewMyTexture(w.renderer, path, colorKey)
+	w.textures = append(w.textures, t)
+	return t
+}
+
+// Renders a texture into this window; it must belong to the window
+func (w *myWindow) draw(t *MyTexture, x, y int32, clip *sdl.Rect) {
+	if t.renderer != w.renderer {
+		panic(fmt.Sprintf("texture of another renderer drawn into window %d", w.id))
+	}
+	t.render(x, y, clip)
+}
+
+func (w *myWindow) handleEvent(e sdl.Event) {
+	if t, ok := e.(*sdl.WindowEvent); ok {
+		switch t.Event {
+		case sdl.WINDOWEVENT_SHOWN:
+			w.shown = true
+		case sdl.WINDOWEVENT_HIDDEN:
+			w.shown = false
+		case sdl.WINDOWEVENT_SIZE_CHANGED:
+			w.width = t.Data1
+			w.height = t.Data2
+			w.renderer.Present()
+		case sdl.WINDOWEVENT_EXPOSED:
+			w.renderer.Present()
+		case sdl.WINDOWEVENT_ENTER:
+			w.mouseFocus = true
+		case sdl.WINDOWEVENT_LEAVE:
+			w.mouseFocus = false
+		case sdl.WINDOWEVENT_FOCUS_GAINED:
+			w.keyboardFocus = true
+		case sdl.WINDOWEVENT_FOCUS_LOST:
+			w.keyboardFocus = false
+		case sdl.WINDOWEVENT_MINIMIZED:
+			w.minimized = true
+		case sdl.WINDOWEVENT_MAXIMIZED, sdl.WINDOWEVENT_RESTORED:
+			w.minimized = false
+		case sdl.WINDOWEVENT_CLOSE:
+			// Closing only hides the window, it can be brought back
+			w.window.Hide()
+		}
+	}
+
+	if w.onEvent != nil {
+		w.onEvent(e)
+	}
+}
+
+// Shows the window if hidden and gives it the focus
+func (w *myWindow) focus() {
+	if !w.shown {
+		w.window.Show()
+	}
+	w.window.Raise()
+}
+
+/* ------------------------------ windowRegistry ------------------------------ */
+
+// All open windows by window ID
+type windowRegistry struct {
+	windows map[uint32]*myWindow
+
+	// Windows in creation order, freed in the same order
+	order []*myWindow
+}
+
+func NewWindowRegistry() *windowRegistry {
+	return &windowRegistry{windows: map[uint32]*myWindow{}}
+}
+
+func (r *windowRegistry) create(title string, width, height int32) (*myWindow, error) {
+	w, err := NewMyWindow(title, width, height)
+	if err != nil {
+		return nil, err
+	}
+	r.windows[w.id] = w
+	r.order = append(r.order, w)
+	return w, nil
+}
+
+func (r *windowRegistry) get(id uint32) *myWindow {
+	return r.windows[id]
+}
+
+// Passes an event to the window it belongs to. Events without a window,
+// like sdl.QuitEvent, go nowhere and are left to the caller.
+func (r *windowRegistry) route(e sdl.Event) {
+	var id uint32
+	switch t := e.(type) {
+	case *sdl.WindowEvent:
+		id = t.WindowID
+	case *sdl.KeyDownEvent:
+		id = t.WindowID
+	case *sdl.KeyUpEvent:
+		id = t.WindowID
+	case *sdl.MouseMotionEvent:
+		id = t.WindowID
+	case *sdl.MouseButtonEvent:
+		id = t.WindowID
+	case *sdl.MouseWheelEvent:
+		id = t.WindowID
+	default:
+		return
+	}
+
+	if w := r.get(id); w != nil {
+		w.handleEvent(e)
+	}
+}
+
+// Whether the user closed every window
+func (r *windowRegistry) allClosed() bool {
+	for _, w := range r.order {
+		if w.shown {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *windowRegistry) free() {
+	for _, w := range r.order {
+		w.free()
+	}
+	r.windows = map[uint32]*myWindow{}
+	r.order = nil
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+// There are several renderers here, so render with the texture's own one
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	t.renderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() error {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return err
+	}
+
+	// Create windows, each with its own renderer
+	gWindows = NewWindowRegistry()
+
+	var err error
+	gGameWindow, err = gWindows.create("Game", SCREEN_WIDTH, SCREEN_HEIGHT)
+	if err != nil {
+		return err
+	}
+	gInspectorWindow, err = gWindows.create("Inspector", TOOL_WIDTH, TOOL_HEIGHT)
+	if err != nil {
+		return err
+	}
+	gPreviewWindow, err = gWindows.create("Preview", TOOL_WIDTH, TOOL_HEIGHT)
+	if err != nil {
+		return err
+	}
+
+	// Init font system
+	return ttf.Init()
+}
+
+func loadMedia(d *dot) {
+	d.texture = gGameWindow.loadTexture("assets/dot.bmp", nil)
+
+	var err error
+	gFont, err = ttf.OpenFont("assets/lazy.ttf", 20)
+	must(err)
+}
+
+func close() {
+	gWindows.free()
+
+	gFont.Close()
+
+	// Quit SDL subsystems
+	ttf.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Shows the dot's state as text
+func renderInspector(w *myWindow, d *dot) {
+	w.renderer.SetDrawColor(32, 32, 32, 255)
+	w.renderer.Clear()
+
+	lines := []string{
+		fmt.Sprintf("x: %d  y: %d", d.x, d.y),
+		fmt.Sprintf("velX: %d  velY: %d", d.velX, d.velY),
+		fmt.Sprintf("game focus: %v", gGameWindow.keyboardFocus),
+	}
+	for i, line := range lines {
+		// Text textures are made for this window's renderer and freed right away
+		text := NewTextMyTexture(w.renderer, line, gFont, sdl.Color{255, 255, 255, 255})
+		w.draw(text, 10, 10+int32(i)*30, nil)
+		text.free()
+	}
+
+	w.renderer.Present()
+}
+
+// Shows the dot zoomed in
+func renderPreview(w *myWindow, texture *MyTexture) {
+	w.renderer.SetDrawColor(255, 255, 255, 255)
+	w.renderer.Clear()
+
+	// The dot texture has to be loaded into this window too
+	w.renderer.SetScale(4, 4)
+	w.draw(texture, (TOOL_WIDTH/4-DOT_WIDTH)/2, (TOOL_HEIGHT/4-DOT_HEIGHT)/2, nil)
+	w.renderer.SetScale(1, 1)
+
+	w.renderer.Present()
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var d dot
+
+	must(initSDL())
+
+	loadMedia(&d)
+
+	// Same image as the dot, but for the preview's renderer
+	previewDot := gPreviewWindow.loadTexture("assets/dot.bmp", nil)
+
+	// Only the game window moves the dot
+	gGameWindow.onEvent = d.handleEvent
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				// Bring windows back with 1 to 3 from any window
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_1:
+					gGameWindow.focus()
+				case sdl.SCANCODE_2:
+					gInspectorWindow.focus()
+				case sdl.SCANCODE_3:
+					gPreviewWindow.focus()
+				}
+			}
+
+			gWindows.route(event)
+		}
+
+		// Quit once every window is closed
+		if gWindows.allClosed() {
+			quit = true
+		}
+
+		// Move the dot
+		d.move()
+
+		// Render the game window
+		if gGameWindow.shown && !gGameWindow.minimized {
+			gGameWindow.renderer.SetDrawColor(255, 255, 255, 255)
+			gGameWindow.renderer.Clear()
+			d.render(gGameWindow)
+			gGameWindow.renderer.Present()
+		}
+
+		// Render the tool windows
+		if gInspectorWindow.shown && !gInspectorWindow.minimized {
+			renderInspector(gInspectorWindow, &d)
+		}
+		if gPreviewWindow.shown && !gPreviewWindow.minimized {
+			renderPreview(gPreviewWindow, previewDot)
+		}
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+	"os"
+	"path/filepath"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+// Where the window geometry is remembered, inside SDL's preference path
+const (
+	PREF_ORG      = "zenja"
+	PREF_APP      = "golang-sdl-tutorials"
+	GEOMETRY_FILE = "lesson37-window.json"
+)
+
+const TEXT_FONT_SIZE = 16
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gFont *ttf.Font
+
+// Display to open the window on; -1 uses the remembered one
+var gDisplayFlag = flag.Int("display", -1, "index of the display to open the window on")
+
+/* ------------------------------ displays ------------------------------ */
+
+type displayInfo struct {
+	index int
+	name  string
+
+	// Whole display, and the part not covered by task bars, docks etc.
+	bounds       sdl.Rect
+	usableBounds sdl.Rect
+
+	// Diagonal, horizontal and vertical DPI; zero if unknown
+	ddpi, hdpi, vdpi float32
+
+	// Available fullscreen modes, best first
+	modes []sdl.DisplayMode
+}
+
+func enumerateDisplays() ([]displayInfo, error) {
+	count, err := sdl.GetNumVideoDisplays()
+	if err != nil {
+		return nil, err
+	}
+
+	displays := make([]displayInfo, count)
+	for i := range displays {
+		d := &displays[i]
+		d.index = i
+
+		if d.name, err = sdl.GetDisplayName(i); err != nil {
+			return nil, err
+		}
+		if d.bounds, err = sdl.GetDisplayBounds(i); err != nil {
+			return nil, err
+		}
+		if d.usableBounds, err = sdl.GetDisplayUsableBounds(i); err != nil {
+			// Not every video driver knows the usable area
+			d.usableBounds = d.bounds
+		}
+
+		// Not every video driver knows the DPI either
+		d.ddpi, d.hdpi, d.vdpi, _ = sdl.GetDisplayDPI(i)
+
+		modeCount, err := sdl.GetNumDisplayModes(i)
+		if err != nil {
+			return nil, err
+		}
+		for m := 0; m < modeCount; m++ {
+			mode, err := sdl.GetDisplayMode(i, m)
+			if err != nil {
+				return nil, err
+			}
+			d.modes = append(d.modes, mode)
+		}
+	}
+	return displays, nil
+}
+
+func modeString(mode sdl.DisplayMode) string {
+	return fmt.Sprintf("%dx%d@%dHz %s", mode.W, mode.H, mode.RefreshRate, sdl.GetPixelFormatName(uint(mode.Format)))
+}
+
+// Centers a window of the given size on the display
+func centerOn(d *displayInfo, width, height int32) (x, y int32) {
+	return d.usableBounds.X + (d.usableBounds.W-width)/2, d.usableBounds.Y + (d.usableBounds.H-height)/2
+}
+
+/* ------------------------------ window geometry ------------------------------ */
+
+// Window placement as saved between runs
+type windowGeometry struct {
+	Display int   `json:"display"`
+	X       int32 `json:"x"`
+	Y       int32 `json:"y"`
+	W       int32 `json:"w"`
+	H       int32 `json:"h"`
+}
+
+func geometryPath() string {
+	return filepath.Join(sdl.GetPrefPath(PREF_ORG, PREF_APP), GEOMETRY_FILE)
+}
+
+func loadGeometry(path string) (*windowGeometry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var g windowGeometry
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func saveGeometry(path string, g *windowGeometry) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Where to open the window: the display from the command line, else the
+// geometry remembered in path if it still fits a display, else the first
+// display
+func initialGeometry(displays []displayInfo, path string) windowGeometry {
+	g := windowGeometry{W: SCREEN_WIDTH, H: SCREEN_HEIGHT}
+
+	if *gDisplayFlag >= 0 && *gDisplayFlag < len(displays) {
+		g.Display = *gDisplayFlag
+		g.X, g.Y = centerOn(&displays[g.Display], g.W, g.H)
+		return g
+	}
+
+	if saved, err := loadGeometry(path); err == nil && saved.Display >= 0 && saved.Display < len(displays) {
+		// The monitor may have been unplugged or rearranged since
+		title := sdl.Rect{saved.X, saved.Y, saved.W, 1}
+		if title.HasIntersection(&displays[saved.Display].bounds) {
+			return *saved
+		}
+		g.Display = saved.Display
+	}
+
+	g.X, g.Y = centerOn(&displays[g.Display], g.W, g.H)
+	return g
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+	}
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL(g windowGeometry) (*sdl.Window, *sdl.Renderer, error) {
+	// Create window at the chosen position
+	window, err := sdl.CreateWindow("test", int(g.X), int(g.Y), int(g.W), int(g.H),
+		sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init font system
+	err = ttf.Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	var err error
+	gFont, err = ttf.OpenFont("assets/lazy.ttf", TEXT_FONT_SIZE)
+	must(err)
+}
+
+func close() {
+	gFont.Close()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	ttf.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Index of the window's fullscreen mode among those of the display it is now
+// on. A mode the display doesn't list goes back to windowed, so the index
+// never points into the list of another display.
+func currentModeIndex(d displayInfo, modeIndex int) int {
+	if modeIndex < 0 {
+		return -1
+	}
+	if mode, err := gWindow.GetDisplayMode(); err == nil {
+		for i, m := range d.modes {
+			if m.W == mode.W && m.H == mode.H && m.RefreshRate == mode.RefreshRate && m.Format == mode.Format {
+				return i
+			}
+		}
+	}
+	must(gWindow.SetFullscreen(0))
+	return -1
+}
+
+// Lists the displays, marking the one the window is on
+func renderDisplays(displays []displayInfo, current int, modeIndex int) {
+	var lines []string
+	for _, d := range displays {
+		marker := "  "
+		if d.index == current {
+			marker = "> "
+		}
+		lines = append(lines,
+			fmt.Sprintf("%s%d: %s", marker, d.index, d.name),
+			fmt.Sprintf("    bounds %v  usable %v", d.bounds, d.usableBounds),
+			fmt.Sprintf("    dpi %.0f (%.0f x %.0f)  %d modes", d.ddpi, d.hdpi, d.vdpi, len(d.modes)))
+	}
+
+	mode := "windowed"
+	if modeIndex >= 0 {
+		mode = "fullscreen " + modeString(displays[current].modes[modeIndex])
+	}
+	lines = append(lines, "", "Mode: "+mode,
+		"Left/Right: move to display  M: next fullscreen mode  W: windowed")
+
+	y := int32(10)
+	for _, line := range lines {
+		if line != "" {
+			text := NewTextMyTexture(gRenderer, line, gFont, sdl.Color{0, 0, 0, 255})
+			text.render(10, y, nil)
+			text.free()
+		}
+		y += TEXT_FONT_SIZE + 4
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	flag.Parse()
+
+	must(sdl.Init(sdl.INIT_EVERYTHING))
+
+	displays, err := enumerateDisplays()
+	must(err)
+	if len(displays) == 0 {
+		panic("no displays found")
+	}
+
+	geometry := initialGeometry(displays, geometryPath())
+
+	gWindow, gRenderer, err = initSDL(geometry)
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	// Display the window is on
+	current := geometry.Display
+
+	// Index of the exclusive fullscreen mode in use, -1 while windowed
+	modeIndex := -1
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.WindowEvent:
+				// The user may drag the window to another display
+				if t.Event == sdl.WINDOWEVENT_MOVED {
+					if index, err := gWindow.GetDisplayIndex(); err == nil && index != current {
+						current = index
+						modeIndex = currentModeIndex(displays[current], modeIndex)
+					}
+				}
+			case *sdl.KeyDownEvent:
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_LEFT, sdl.SCANCODE_RIGHT:
+					if modeIndex >= 0 {
+						break
+					}
+					if t.Keysym.Scancode == sdl.SCANCODE_LEFT {
+						current = (current + len(displays) - 1) % len(displays)
+					} else {
+						current = (current + 1) % len(displays)
+					}
+					w, h := gWindow.GetSize()
+					x, y := centerOn(&displays[current], int32(w), int32(h))
+					gWindow.SetPosition(int(x), int(y))
+				case sdl.SCANCODE_M:
+					// Cycle through the modes of the current display
+					if len(displays[current].modes) == 0 {
+						break
+					}
+					modeIndex = (modeIndex + 1) % len(displays[current].modes)
+					mode := displays[current].modes[modeIndex]
+					must(gWindow.SetDisplayMode(&mode))
+					must(gWindow.SetFullscreen(sdl.WINDOW_FULLSCREEN))
+				case sdl.SCANCODE_W:
+					must(gWindow.SetFullscreen(0))
+					modeIndex = -1
+				}
+			}
+		}
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		renderDisplays(displays, current, modeIndex)
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	// Remember where the window was, unless it is fullscreen
+	if modeIndex < 0 {
+		x, y := gWindow.GetPosition()
+		w, h := gWindow.GetSize()
+		err := saveGeometry(geometryPath(), &windowGeometry{current, int32(x), int32(y), int32(w), int32(h)})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "could not save window geometry:", err)
+		}
+	}
+
+	close()
+}
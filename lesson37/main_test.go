@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The dummy video driver has a display, so this runs headless
+func TestMain(m *testing.M) {
+	os.Setenv("SDL_VIDEODRIVER", "dummy")
+	if err := sdl.Init(sdl.INIT_VIDEO); err != nil {
+		fmt.Fprintln(os.Stderr, "could not init SDL:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	sdl.Quit()
+	os.Exit(code)
+}
+
+func TestEnumerateDisplays(t *testing.T) {
+	displays, err := enumerateDisplays()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(displays) == 0 {
+		t.Fatal("no displays")
+	}
+
+	for i, d := range displays {
+		if d.index != i {
+			t.Errorf("display %d has index %d", i, d.index)
+		}
+		if d.bounds.W <= 0 || d.bounds.H <= 0 {
+			t.Errorf("display %d: empty bounds %v", i, d.bounds)
+		}
+		if u, ok := d.usableBounds.Intersect(&d.bounds); !ok || u != d.usableBounds {
+			t.Errorf("display %d: usable bounds %v outside of %v", i, d.usableBounds, d.bounds)
+		}
+		if len(d.modes) == 0 {
+			t.Errorf("display %d: no modes", i)
+		}
+	}
+}
+
+func TestGeometryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), GEOMETRY_FILE)
+	want := windowGeometry{1, -20, 30, 800, 600}
+	if err := saveGeometry(path, &want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadGeometry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+}
+
+func TestInitialGeometryDummyDisplay(t *testing.T) {
+	displays, err := enumerateDisplays()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing saved yet: centered on the first display
+	g := initialGeometry(displays, filepath.Join(t.TempDir(), GEOMETRY_FILE))
+	x, y := centerOn(&displays[0], SCREEN_WIDTH, SCREEN_HEIGHT)
+	want := windowGeometry{0, x, y, SCREEN_WIDTH, SCREEN_HEIGHT}
+	if g != want {
+		t.Errorf("got %+v, want %+v", g, want)
+	}
+}
+
+func TestInitialGeometry(t *testing.T) {
+	// Two displays side by side
+	displays := []displayInfo{
+		{index: 0, bounds: sdl.Rect{0, 0, 1920, 1080}, usableBounds: sdl.Rect{0, 0, 1920, 1040}},
+		{index: 1, bounds: sdl.Rect{1920, 0, 1280, 1024}, usableBounds: sdl.Rect{1920, 0, 1280, 1024}},
+	}
+	center := func(display int) windowGeometry {
+		x, y := centerOn(&displays[display], SCREEN_WIDTH, SCREEN_HEIGHT)
+		return windowGeometry{display, x, y, SCREEN_WIDTH, SCREEN_HEIGHT}
+	}
+
+	for _, c := range []struct {
+		name  string
+		saved *windowGeometry
+		flag  int
+		want  windowGeometry
+	}{
+		{"nothing saved", nil, -1, center(0)},
+		{"saved on the second display", &windowGeometry{1, 2000, 100, 800, 600}, -1, windowGeometry{1, 2000, 100, 800, 600}},
+		{"saved off the display", &windowGeometry{1, 5000, 100, 800, 600}, -1, center(1)},
+		{"saved display unplugged", &windowGeometry{5, 100, 100, 800, 600}, -1, center(0)},
+		{"saved display negative", &windowGeometry{-1, 100, 100, 800, 600}, -1, center(0)},
+		{"command line wins", &windowGeometry{0, 100, 100, 800, 600}, 1, center(1)},
+	} {
+		path := filepath.Join(t.TempDir(), GEOMETRY_FILE)
+		if c.saved != nil {
+			if err := saveGeometry(path, c.saved); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		*gDisplayFlag = c.flag
+		got := initialGeometry(displays, path)
+		if got != c.want {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+	*gDisplayFlag = -1
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"math"
+	"math/rand"
+	"os"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 3
+
+// Emitter definitions by name
+const EMITTER_CONFIG = "assets/emitters.json"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+
+var gEmitterConfigs map[string]emitterConfig
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y       int32
+	velX, velY int32
+
+	// Sparkles left behind while moving
+	trail *emitter
+}
+
+func NewDot(renderer *sdl.Renderer) *dot {
+	return &dot{trail: NewEmitter(renderer, gEmitterConfigs["sparkle"])}
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX -= DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX += DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+func (d *dot) move(dt float64) {
+	// Move the dot left or right
+	d.x += d.velX
+
+	// If the dot went too far to the left or right
+	if d.x < 0 || d.x+DOT_WIDTH > SCREEN_WIDTH {
+		d.x -= d.velX
+	}
+
+	// Move the dot up or down
+	d.y += d.velY
+
+	// If the dot went too far to the up or down
+	if d.y < 0 || d.y+DOT_HEIGHT > SCREEN_HEIGHT {
+		d.y -= d.velY
+	}
+
+	// Only sparkle while moving
+	d.trail.setPosition(float64(d.x+DOT_WIDTH/2), float64(d.y+DOT_HEIGHT/2))
+	d.trail.active = d.velX != 0 || d.velY != 0
+	d.trail.update(dt)
+}
+
+func (d *dot) render() {
+	// Sparkles go behind the dot
+	d.trail.render()
+	gDotTexture.render(d.x, d.y, nil)
+}
+
+// A color at a point of a particle's life, where 0 is birth and 1 is death
+type colorStop struct {
+	T float64 `json:"t"`
+	R float64 `json:"r"`
+	G float64 `json:"g"`
+	B float64 `json:"b"`
+}
+
+type alphaStop struct {
+	T float64 `json:"t"`
+	A float64 `json:"a"`
+}
+
+// Emitter definition as found in the config file
+type emitterConfig struct {
+	Texture  string `json:"texture"`
+	Additive bool   `json:"additive"`
+
+	// Upper bound of particles alive at once
+	MaxParticles int `json:"maxParticles"`
+
+	// Particles per second while active, and particles emitted by a burst
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+
+	// Lifetime in seconds
+	LifeMin float64 `json:"lifeMin"`
+	LifeMax float64 `json:"lifeMax"`
+
+	// Initial speed in pixels per second, in a direction given in degrees
+	SpeedMin float64 `json:"speedMin"`
+	SpeedMax float64 `json:"speedMax"`
+	Angle    float64 `json:"angle"`
+	Spread   float64 `json:"spread"`
+
+	// Acceleration in pixels per second squared
+	GravityX float64 `json:"gravityX"`
+	GravityY float64 `json:"gravityY"`
+
+	Color []colorStop `json:"color"`
+	Alpha []alphaStop `json:"alpha"`
+}
+
+func loadEmitterConfigs(path string) (map[string]emitterConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := map[string]emitterConfig{}
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// Interpolates the color curve at life fraction t
+func (c *emitterConfig) colorAt(t float64) (r, g, b uint8) {
+	stops := c.Color
+	if len(stops) == 0 {
+		return 255, 255, 255
+	}
+	if t <= stops[0].T {
+		return uint8(stops[0].R), uint8(stops[0].G), uint8(stops[0].B)
+	}
+	for i := 1; i < len(stops); i++ {
+		if t <= stops[i].T {
+			from, to := stops[i-1], stops[i]
+			f := (t - from.T) / (to.T - from.T)
+			return uint8(lerp(from.R, to.R, f)), uint8(lerp(from.G, to.G, f)), uint8(lerp(from.B, to.B, f))
+		}
+	}
+	last := stops[len(stops)-1]
+	return uint8(last.R), uint8(last.G), uint8(last.B)
+}
+
+// Interpolates the alpha curve at life fraction t
+func (c *emitterConfig) alphaAt(t float64) uint8 {
+	stops := c.Alpha
+	if len(stops) == 0 {
+		return 255
+	}
+	if t <= stops[0].T {
+		return uint8(stops[0].A)
+	}
+	for i := 1; i < len(stops); i++ {
+		if t <= stops[i].T {
+			from, to := stops[i-1], stops[i]
+			return uint8(lerp(from.A, to.A, (t-from.T)/(to.T-from.T)))
+		}
+	}
+	return uint8(stops[len(stops)-1].A)
+}
+
+func lerp(a, b, f float64) float64 {
+	return a + (b-a)*f
+}
+
+func randRange(min, max float64) float64 {
+	return min + rand.Float64()*(max-min)
+}
+
+type particle struct {
+	x, y       float64
+	velX, velY float64
+
+	// Seconds lived and seconds to live
+	age, life float64
+}
+
+type emitter struct {
+	emitterConfig
+
+	texture *MyTexture
+
+	// Where particles are spawned
+	x, y float64
+
+	// Whether particles are spawned continuously
+	active bool
+
+	// Particle pool; the first alive entries are in use
+	particles []particle
+	alive     int
+
+	// Fractional particles carried over to the next update
+	spawnDebt float64
+}
+
+func NewEmitter(renderer *sdl.Renderer, config emitterConfig) *emitter {
+	e := &emitter{
+		emitterConfig: config,
+		texture:       NewMyTexture(renderer, config.Texture, nil),
+		particles:     make([]particle, config.MaxParticles),
+	}
+	if config.Additive {
+		e.texture.setBlendMode(sdl.BLENDMODE_ADD)
+	} else {
+		e.texture.setBlendMode(sdl.BLENDMODE_BLEND)
+	}
+	return e
+}
+
+func (e *emitter) free() {
+	e.texture.free()
+}
+
+func (e *emitter) setPosition(x, y float64) {
+	e.x = x
+	e.y = y
+}
+
+// Spawns a single particle, unless the pool is used up
+func (e *emitter) spawn() {
+	if e.alive == len(e.particles) {
+		return
+	}
+
+	angle := (e.Angle + randRange(-e.Spread/2, e.Spread/2)) * math.Pi / 180
+	speed := randRange(e.SpeedMin, e.SpeedMax)
+
+	p := &e.particles[e.alive]
+	p.x = e.x
+	p.y = e.y
+	p.velX = math.Cos(angle) * speed
+	p.velY = math.Sin(angle) * speed
+	p.age = 0
+	p.life = randRange(e.LifeMin, e.LifeMax)
+	e.alive++
+}
+
+func (e *emitter) burst() {
+	for i := 0; i < e.Burst; i++ {
+		e.spawn()
+	}
+}
+
+func (e *emitter) update(dt float64) {
+	// Spawn new particles
+	if e.active {
+		e.spawnDebt += e.Rate * dt
+		for ; e.spawnDebt >= 1; e.spawnDebt-- {
+			e.spawn()
+		}
+	} else {
+		e.spawnDebt = 0
+	}
+
+	// Move particles; dead ones are replaced by the last alive one
+	for i := 0; i < e.alive; {
+		p := &e.particles[i]
+		p.age += dt
+		if p.age >= p.life {
+			e.alive--
+			e.particles[i] = e.particles[e.alive]
+			continue
+		}
+
+		p.velX += e.GravityX * dt
+		p.velY += e.GravityY * dt
+		p.x += p.velX * dt
+		p.y += p.velY * dt
+		i++
+	}
+}
+
+func (e *emitter) render() {
+	for i := 0; i < e.alive; i++ {
+		p := &e.particles[i]
+		t := p.age / p.life
+
+		e.texture.setColor(e.colorAt(t))
+		e.texture.setAlpha(e.alphaAt(t))
+		e.texture.render(int32(p.x)-e.texture.width/2, int32(p.y)-e.texture.height/2, nil)
+	}
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+
+	var err error
+	gEmitterConfigs, err = loadEmitterConfigs(EMITTER_CONFIG)
+	must(err)
+}
+
+func close() {
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	gDotTexture.free()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	d := NewDot(gRenderer)
+
+	// Fireworks fired with the mouse
+	fireworks := NewEmitter(gRenderer, gEmitterConfigs["firework"])
+
+	lastTicks := sdl.GetTicks()
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.MouseButtonEvent:
+				if t.Type == sdl.MOUSEBUTTONDOWN {
+					fireworks.setPosition(float64(t.X), float64(t.Y))
+					fireworks.burst()
+				}
+			}
+
+			d.handleEvent(event)
+		}
+
+		// Seconds since the last frame
+		ticks := sdl.GetTicks()
+		dt := float64(ticks-lastTicks) / 1000
+		lastTicks = ticks
+
+		// Move the dot and its trail, then the fireworks
+		d.move(dt)
+		fireworks.update(dt)
+
+		// Clear screen
+		gRenderer.SetDrawColor(0, 0, 0, 255)
+		gRenderer.Clear()
+
+		d.render()
+		fireworks.render()
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	d.trail.free()
+	fireworks.free()
+
+	close()
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 4
+
+// The same level exported from Tiled in both formats
+var MAP_FILES = []string{"assets/level.tmx", "assets/level.json"}
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+
+var gTileMap *tileMap
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	// Collision box, in level coordinates
+	box sdl.Rect
+
+	velX, velY int32
+}
+
+func NewDot(x, y int32) *dot {
+	return &dot{box: sdl.Rect{x, y, DOT_WIDTH, DOT_HEIGHT}}
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX -= DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX += DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+// Move the dot and step back if it ran into a solid tile
+func (d *dot) move(m *tileMap) {
+	d.box.X += d.velX
+	if m.touchesSolid(&d.box) {
+		d.box.X -= d.velX
+	}
+
+	d.box.Y += d.velY
+	if m.touchesSolid(&d.box) {
+		d.box.Y -= d.velY
+	}
+}
+
+// Center the camera over the dot and keep it inside the map
+func (d *dot) updateCamera(camera *sdl.Rect, m *tileMap) {
+	camera.X = (d.box.X + DOT_WIDTH/2) - SCREEN_WIDTH/2
+	camera.Y = (d.box.Y + DOT_HEIGHT/2) - SCREEN_HEIGHT/2
+
+	if camera.X > m.pixelWidth()-camera.W {
+		camera.X = m.pixelWidth() - camera.W
+	}
+	if camera.Y > m.pixelHeight()-camera.H {
+		camera.Y = m.pixelHeight() - camera.H
+	}
+	if camera.X < 0 {
+		camera.X = 0
+	}
+	if camera.Y < 0 {
+		camera.Y = 0
+	}
+}
+
+func (d *dot) render(camera *sdl.Rect) {
+	gDotTexture.render(d.box.X-camera.X, d.box.Y-camera.Y, nil)
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	must(t.reloadFromFile(path, colorKey))
+}
+
+// Like loadFromFile, but returns the error and keeps the current texture if
+// the file can't be loaded
+func (t *MyTexture) reloadFromFile(path string, colorKey *sdl.Color) error {
+	surface, err := img.Load(path)
+	if err != nil {
+		return err
+	}
+	defer surface.Free()
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	texture, err := t.renderer.CreateTextureFromSurface(surface)
+	if err != nil {
+		return err
+	}
+
+	// Free pre-existing texture
+	t.free()
+	t.texture = texture
+	t.width = surface.W
+	t.height = surface.H
+	return nil
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+func (t *MyTexture) renderRotationFlip(x, y int32, clip *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.CopyEx(t.texture, clip, renderQuad, angle, center, flip)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia(mapFile string) {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+
+	var err error
+	gTileMap, err = loadTileMap(gRenderer, mapFile)
+	must(err)
+	gWindow.SetTitle(gTileMap.properties["title"] + " (" + mapFile + ")")
+}
+
+func close() {
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	gDotTexture.free()
+	gTileMap.free()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	mapIndex := 0
+	loadMedia(MAP_FILES[mapIndex])
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	// Start at the spawn point placed in the object layer
+	spawn := gTileMap.findObject("spawn")
+	d := NewDot(int32(spawn.x), int32(spawn.y))
+
+	// The camera area
+	camera := &sdl.Rect{0, 0, SCREEN_WIDTH, SCREEN_HEIGHT}
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				// Switch between the TMX and the JSON export
+				if t.Keysym.Scancode == sdl.SCANCODE_TAB {
+					mapIndex = (mapIndex + 1) % len(MAP_FILES)
+					m, err := loadTileMap(gRenderer, MAP_FILES[mapIndex])
+					must(err)
+					gTileMap.free()
+					gTileMap = m
+					gWindow.SetTitle(gTileMap.properties["title"] + " (" + MAP_FILES[mapIndex] + ")")
+				}
+			}
+
+			d.handleEvent(event)
+		}
+
+		// Move the dot and the camera following it
+		d.move(gTileMap)
+		d.updateCamera(camera, gTileMap)
+
+		// Tint the dot while it stands in a door
+		gDotTexture.setColor(255, 255, 255)
+		if objects := gTileMap.objectLayer("objects"); objects != nil {
+			for _, o := range objects.objects {
+				r := o.rect()
+				if o.typ == "door" && d.box.HasIntersection(&r) {
+					gDotTexture.setColor(255, 128, 128)
+				}
+			}
+		}
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// Render visible tiles, then the dot
+		gTileMap.render(camera)
+		d.render(camera)
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
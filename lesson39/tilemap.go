@@ -0,0 +1,828 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Tiled stores flip flags in the highest bits of a global tile ID
+const (
+	FLIPPED_HORIZONTALLY = 0x80000000
+	FLIPPED_VERTICALLY   = 0x40000000
+	FLIPPED_DIAGONALLY   = 0x20000000
+	GID_MASK             = 0x1FFFFFFF
+)
+
+/* ------------------------------ map types ------------------------------ */
+
+// Custom properties as set in the Tiled editor; values are kept as strings
+type properties map[string]string
+
+func (p properties) bool(name string) bool {
+	v, _ := strconv.ParseBool(p[name])
+	return v
+}
+
+type tileset struct {
+	firstGID int
+
+	name string
+
+	tileWidth, tileHeight int32
+	margin, spacing       int32
+	columns, tileCount    int32
+
+	texture *MyTexture
+
+	// Properties of single tiles by local tile ID
+	tileProperties map[int]properties
+}
+
+// Source rectangle of a tile inside the tileset image
+func (ts *tileset) clip(localID int) *sdl.Rect {
+	col := int32(localID) % ts.columns
+	row := int32(localID) / ts.columns
+	return &sdl.Rect{
+		ts.margin + col*(ts.tileWidth+ts.spacing),
+		ts.margin + row*(ts.tileHeight+ts.spacing),
+		ts.tileWidth,
+		ts.tileHeight,
+	}
+}
+
+type tileLayer struct {
+	name          string
+	width, height int32
+	visible       bool
+	properties    properties
+
+	// Global tile IDs including flip flags, row by row; 0 is empty
+	data []uint32
+}
+
+func (l *tileLayer) gid(col, row int32) uint32 {
+	if col < 0 || row < 0 || col >= l.width || row >= l.height {
+		return 0
+	}
+	return l.data[row*l.width+col]
+}
+
+type mapObject struct {
+	id         int
+	name       string
+	typ        string
+	x, y, w, h float64
+	properties properties
+}
+
+func (o *mapObject) rect() sdl.Rect {
+	return sdl.Rect{int32(o.x), int32(o.y), int32(o.w), int32(o.h)}
+}
+
+type objectLayer struct {
+	name       string
+	objects    []*mapObject
+	properties properties
+}
+
+type tileMap struct {
+	// Map size in tiles
+	width, height int32
+
+	tileWidth, tileHeight int32
+
+	properties properties
+
+	tilesets     []*tileset
+	layers       []*tileLayer
+	objectLayers []*objectLayer
+}
+
+// Loads a map exported by Tiled, either as .tmx or as .json
+func loadTileMap(renderer *sdl.Renderer, path string) (*tileMap, error) {
+	var raw *rawMap
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx":
+		raw, err = readTMX(path)
+	case ".json", ".tmj":
+		raw, err = readTiledJSON(path)
+	default:
+		return nil, fmt.Errorf("unknown map format: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if raw.Orientation != "" && raw.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("%s: unsupported orientation %q", path, raw.Orientation)
+	}
+	return raw.build(renderer, filepath.Dir(path))
+}
+
+func (m *tileMap) free() {
+	for _, ts := range m.tilesets {
+		ts.texture.free()
+	}
+}
+
+// Level size in pixels
+func (m *tileMap) pixelWidth() int32 {
+	return m.width * m.tileWidth
+}
+
+func (m *tileMap) pixelHeight() int32 {
+	return m.height * m.tileHeight
+}
+
+// Finds the tileset a global tile ID belongs to
+func (m *tileMap) tilesetFor(gid int) *tileset {
+	var found *tileset
+	for _, ts := range m.tilesets {
+		if ts.firstGID <= gid {
+			found = ts
+		}
+	}
+	return found
+}
+
+func (m *tileMap) objectLayer(name string) *objectLayer {
+	for _, ol := range m.objectLayers {
+		if ol.name == name {
+			return ol
+		}
+	}
+	return nil
+}
+
+func (m *tileMap) findObject(name string) *mapObject {
+	for _, ol := range m.objectLayers {
+		for _, o := range ol.objects {
+			if o.name == name {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// Renders the tiles inside the camera area; tiles outside are skipped
+func (m *tileMap) render(camera *sdl.Rect) {
+	firstCol := camera.X / m.tileWidth
+	firstRow := camera.Y / m.tileHeight
+	lastCol := (camera.X + camera.W - 1) / m.tileWidth
+	lastRow := (camera.Y + camera.H - 1) / m.tileHeight
+
+	for _, l := range m.layers {
+		if !l.visible {
+			continue
+		}
+		for row := firstRow; row <= lastRow; row++ {
+			for col := firstCol; col <= lastCol; col++ {
+				m.renderTile(l.gid(col, row), col*m.tileWidth-camera.X, row*m.tileHeight-camera.Y)
+			}
+		}
+	}
+}
+
+func (m *tileMap) renderTile(raw uint32, x, y int32) {
+	gid := int(raw & GID_MASK)
+	if gid == 0 {
+		return
+	}
+	ts := m.tilesetFor(gid)
+	if ts == nil {
+		return
+	}
+
+	// Tiles bigger than the grid are aligned to the bottom of their cell
+	y += m.tileHeight - ts.tileHeight
+
+	if raw&(FLIPPED_HORIZONTALLY|FLIPPED_VERTICALLY|FLIPPED_DIAGONALLY) == 0 {
+		ts.texture.render(x, y, ts.clip(gid-ts.firstGID))
+		return
+	}
+
+	angle, flip := tileFlip(raw)
+	ts.texture.renderRotationFlip(x, y, ts.clip(gid-ts.firstGID), angle, nil, flip)
+}
+
+// Turns the flip flags of a global tile ID into what SDL draws with. SDL
+// flips before rotating, so a diagonal flip becomes a quarter turn with the
+// horizontal and vertical flags swapped around.
+func tileFlip(raw uint32) (float64, sdl.RendererFlip) {
+	flipH := raw&FLIPPED_HORIZONTALLY != 0
+	flipV := raw&FLIPPED_VERTICALLY != 0
+
+	var angle float64
+	if raw&FLIPPED_DIAGONALLY != 0 {
+		angle = 90
+		flipH, flipV = flipV, !flipH
+	}
+	var flip sdl.RendererFlip = sdl.FLIP_NONE
+	if flipH {
+		flip |= sdl.FLIP_HORIZONTAL
+	}
+	if flipV {
+		flip |= sdl.FLIP_VERTICAL
+	}
+	return angle, flip
+}
+
+// A tile is solid if the tile itself or its layer has the "solid" property
+func (m *tileMap) isSolid(col, row int32) bool {
+	if col < 0 || row < 0 || col >= m.width || row >= m.height {
+		return true
+	}
+	for _, l := range m.layers {
+		gid := int(l.gid(col, row) & GID_MASK)
+		if gid == 0 {
+			continue
+		}
+		if l.properties.bool("solid") {
+			return true
+		}
+		ts := m.tilesetFor(gid)
+		if ts != nil && ts.tileProperties[gid-ts.firstGID].bool("solid") {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks whether a box in level coordinates overlaps any solid tile
+func (m *tileMap) touchesSolid(box *sdl.Rect) bool {
+	for row := floorDiv(box.Y, m.tileHeight); row <= floorDiv(box.Y+box.H-1, m.tileHeight); row++ {
+		for col := floorDiv(box.X, m.tileWidth); col <= floorDiv(box.X+box.W-1, m.tileWidth); col++ {
+			if m.isSolid(col, row) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Division rounding down rather than toward zero, so a box just left of or
+// above the map lands in column or row -1, outside it
+func floorDiv(a, b int32) int32 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+/* ------------------------------ format independent raw data ------------------------------ */
+
+// Both formats are read into these raw types first, which then get turned
+// into a tileMap with textures.
+
+type rawProperty struct {
+	Name  string
+	Value string
+}
+
+type rawTile struct {
+	ID         int
+	Properties []rawProperty
+}
+
+type rawTileset struct {
+	FirstGID   int
+	Name       string
+	TileWidth  int32
+	TileHeight int32
+	Margin     int32
+	Spacing    int32
+	Columns    int32
+	TileCount  int32
+	Image      string
+	Tiles      []rawTile
+}
+
+type rawObject struct {
+	ID         int
+	Name       string
+	Type       string
+	X, Y, W, H float64
+	Properties []rawProperty
+}
+
+type rawLayer struct {
+	Name       string
+	Objects    bool
+	Width      int32
+	Height     int32
+	Visible    bool
+	Data       []uint32
+	ObjectList []rawObject
+	Properties []rawProperty
+}
+
+type rawMap struct {
+	Orientation string
+	Width       int32
+	Height      int32
+	TileWidth   int32
+	TileHeight  int32
+	Properties  []rawProperty
+	Tilesets    []rawTileset
+	Layers      []rawLayer
+}
+
+func toProperties(raw []rawProperty) properties {
+	p := properties{}
+	for _, rp := range raw {
+		p[rp.Name] = rp.Value
+	}
+	return p
+}
+
+func (rm *rawMap) build(renderer *sdl.Renderer, dir string) (*tileMap, error) {
+	m := &tileMap{
+		width:      rm.Width,
+		height:     rm.Height,
+		tileWidth:  rm.TileWidth,
+		tileHeight: rm.TileHeight,
+		properties: toProperties(rm.Properties),
+	}
+
+	for _, rts := range rm.Tilesets {
+		if rts.Image == "" {
+			m.free()
+			return nil, fmt.Errorf("tileset %q: image collections are not supported", rts.Name)
+		}
+		if rts.Columns <= 0 {
+			m.free()
+			return nil, fmt.Errorf("tileset %q: missing column count", rts.Name)
+		}
+		ts := &tileset{
+			firstGID:       rts.FirstGID,
+			name:           rts.Name,
+			tileWidth:      rts.TileWidth,
+			tileHeight:     rts.TileHeight,
+			margin:         rts.Margin,
+			spacing:        rts.Spacing,
+			columns:        rts.Columns,
+			tileCount:      rts.TileCount,
+			tileProperties: map[int]properties{},
+		}
+		for _, t := range rts.Tiles {
+			ts.tileProperties[t.ID] = toProperties(t.Properties)
+		}
+		ts.texture = &MyTexture{renderer: renderer}
+		if err := ts.texture.reloadFromFile(filepath.Join(dir, rts.Image), nil); err != nil {
+			m.free()
+			return nil, fmt.Errorf("tileset %q: %v", rts.Name, err)
+		}
+		m.tilesets = append(m.tilesets, ts)
+	}
+
+	for _, rl := range rm.Layers {
+		if rl.Objects {
+			ol := &objectLayer{name: rl.Name, properties: toProperties(rl.Properties)}
+			for _, ro := range rl.ObjectList {
+				ol.objects = append(ol.objects, &mapObject{
+					id:         ro.ID,
+					name:       ro.Name,
+					typ:        ro.Type,
+					x:          ro.X,
+					y:          ro.Y,
+					w:          ro.W,
+					h:          ro.H,
+					properties: toProperties(ro.Properties),
+				})
+			}
+			m.objectLayers = append(m.objectLayers, ol)
+			continue
+		}
+
+		if int32(len(rl.Data)) != rl.Width*rl.Height {
+			m.free()
+			return nil, fmt.Errorf("layer %q: expected %d tiles, got %d", rl.Name, rl.Width*rl.Height, len(rl.Data))
+		}
+		m.layers = append(m.layers, &tileLayer{
+			name:       rl.Name,
+			width:      rl.Width,
+			height:     rl.Height,
+			visible:    rl.Visible,
+			properties: toProperties(rl.Properties),
+			data:       rl.Data,
+		})
+	}
+
+	return m, nil
+}
+
+// Decodes tile data stored as CSV or as base64 with optional compression
+func decodeTileData(encoding, compression, text string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		var data []uint32
+		for _, field := range strings.Split(text, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			v, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, uint32(v))
+		}
+		return data, nil
+	case "base64":
+		b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return nil, err
+		}
+
+		var r io.ReadCloser
+		switch compression {
+		case "":
+			r = io.NopCloser(bytes.NewReader(b))
+		case "zlib":
+			if r, err = zlib.NewReader(bytes.NewReader(b)); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			if r, err = gzip.NewReader(bytes.NewReader(b)); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported compression %q", compression)
+		}
+		b, err = io.ReadAll(r)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+
+		// Close reports a bad checksum in the trailer
+		if err := r.Close(); err != nil {
+			return nil, err
+		}
+
+		data := make([]uint32, len(b)/4)
+		for i := range data {
+			data[i] = binary.LittleEndian.Uint32(b[i*4:])
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+/* ------------------------------ TMX ------------------------------ */
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type tmxTile struct {
+	ID         int           `xml:"id,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxTileset struct {
+	FirstGID   int    `xml:"firstgid,attr"`
+	Source     string `xml:"source,attr"`
+	Name       string `xml:"name,attr"`
+	TileWidth  int32  `xml:"tilewidth,attr"`
+	TileHeight int32  `xml:"tileheight,attr"`
+	Margin     int32  `xml:"margin,attr"`
+	Spacing    int32  `xml:"spacing,attr"`
+	Columns    int32  `xml:"columns,attr"`
+	TileCount  int32  `xml:"tilecount,attr"`
+	Image      struct {
+		Source string `xml:"source,attr"`
+	} `xml:"image"`
+	Tiles []tmxTile `xml:"tile"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+}
+
+type tmxMap struct {
+	Orientation string        `xml:"orientation,attr"`
+	Width       int32         `xml:"width,attr"`
+	Height      int32         `xml:"height,attr"`
+	TileWidth   int32         `xml:"tilewidth,attr"`
+	TileHeight  int32         `xml:"tileheight,attr"`
+	Properties  []tmxProperty `xml:"properties>property"`
+	Tilesets    []tmxTileset  `xml:"tileset"`
+
+	// Tile and object layers are kept in document order
+	Layers []struct {
+		XMLName    xml.Name
+		Name       string        `xml:"name,attr"`
+		Width      int32         `xml:"width,attr"`
+		Height     int32         `xml:"height,attr"`
+		Visible    *int          `xml:"visible,attr"`
+		Properties []tmxProperty `xml:"properties>property"`
+		Data       tmxData       `xml:"data"`
+		Objects    []tmxObject   `xml:"object"`
+	} `xml:",any"`
+}
+
+func tmxProperties(props []tmxProperty) []rawProperty {
+	var raw []rawProperty
+	for _, p := range props {
+		// Multi-line string properties are stored as element text
+		value := p.Value
+		if value == "" {
+			value = p.Text
+		}
+		raw = append(raw, rawProperty{p.Name, value})
+	}
+	return raw
+}
+
+func readTMXTileset(ts tmxTileset) rawTileset {
+	raw := rawTileset{
+		FirstGID:   ts.FirstGID,
+		Name:       ts.Name,
+		TileWidth:  ts.TileWidth,
+		TileHeight: ts.TileHeight,
+		Margin:     ts.Margin,
+		Spacing:    ts.Spacing,
+		Columns:    ts.Columns,
+		TileCount:  ts.TileCount,
+		Image:      ts.Image.Source,
+	}
+	for _, t := range ts.Tiles {
+		raw.Tiles = append(raw.Tiles, rawTile{t.ID, tmxProperties(t.Properties)})
+	}
+	return raw
+}
+
+func readTMX(path string) (*rawMap, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tm tmxMap
+	if err := xml.Unmarshal(b, &tm); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	rm := &rawMap{
+		Orientation: tm.Orientation,
+		Width:       tm.Width,
+		Height:      tm.Height,
+		TileWidth:   tm.TileWidth,
+		TileHeight:  tm.TileHeight,
+		Properties:  tmxProperties(tm.Properties),
+	}
+
+	for _, ts := range tm.Tilesets {
+		// External tilesets live in their own .tsx file
+		if ts.Source != "" {
+			tsPath := filepath.Join(filepath.Dir(path), ts.Source)
+			b, err := os.ReadFile(tsPath)
+			if err != nil {
+				return nil, err
+			}
+			var ext tmxTileset
+			if err := xml.Unmarshal(b, &ext); err != nil {
+				return nil, fmt.Errorf("%s: %v", tsPath, err)
+			}
+			ext.FirstGID = ts.FirstGID
+			ext.Image.Source = filepath.Join(filepath.Dir(ts.Source), ext.Image.Source)
+			ts = ext
+		}
+		rm.Tilesets = append(rm.Tilesets, readTMXTileset(ts))
+	}
+
+	for _, l := range tm.Layers {
+		rl := rawLayer{
+			Name:       l.Name,
+			Width:      l.Width,
+			Height:     l.Height,
+			Visible:    l.Visible == nil || *l.Visible != 0,
+			Properties: tmxProperties(l.Properties),
+		}
+		switch l.XMLName.Local {
+		case "layer":
+			rl.Data, err = decodeTileData(l.Data.Encoding, l.Data.Compression, l.Data.Text)
+			if err != nil {
+				return nil, fmt.Errorf("%s: layer %q: %v", path, l.Name, err)
+			}
+		case "objectgroup":
+			rl.Objects = true
+			for _, o := range l.Objects {
+				typ := o.Type
+				if typ == "" {
+					typ = o.Class
+				}
+				rl.ObjectList = append(rl.ObjectList, rawObject{
+					o.ID, o.Name, typ, o.X, o.Y, o.Width, o.Height, tmxProperties(o.Properties),
+				})
+			}
+		default:
+			continue
+		}
+		rm.Layers = append(rm.Layers, rl)
+	}
+
+	return rm, nil
+}
+
+/* ------------------------------ JSON ------------------------------ */
+
+type jsonProperty struct {
+	Name string `json:"name"`
+
+	// Bools and numbers are not quoted
+	Value interface{} `json:"value"`
+}
+
+type jsonTileset struct {
+	FirstGID   int    `json:"firstgid"`
+	Source     string `json:"source"`
+	Name       string `json:"name"`
+	TileWidth  int32  `json:"tilewidth"`
+	TileHeight int32  `json:"tileheight"`
+	Margin     int32  `json:"margin"`
+	Spacing    int32  `json:"spacing"`
+	Columns    int32  `json:"columns"`
+	TileCount  int32  `json:"tilecount"`
+	Image      string `json:"image"`
+	Tiles      []struct {
+		ID         int            `json:"id"`
+		Properties []jsonProperty `json:"properties"`
+	} `json:"tiles"`
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonLayer struct {
+	Type        string         `json:"type"`
+	Name        string         `json:"name"`
+	Width       int32          `json:"width"`
+	Height      int32          `json:"height"`
+	Visible     *bool          `json:"visible"`
+	Encoding    string         `json:"encoding"`
+	Compression string         `json:"compression"`
+	Properties  []jsonProperty `json:"properties"`
+	Objects     []jsonObject   `json:"objects"`
+
+	// Either an array of IDs or a base64 string
+	Data json.RawMessage `json:"data"`
+}
+
+type jsonMap struct {
+	Orientation string         `json:"orientation"`
+	Width       int32          `json:"width"`
+	Height      int32          `json:"height"`
+	TileWidth   int32          `json:"tilewidth"`
+	TileHeight  int32          `json:"tileheight"`
+	Properties  []jsonProperty `json:"properties"`
+	Tilesets    []jsonTileset  `json:"tilesets"`
+	Layers      []jsonLayer    `json:"layers"`
+}
+
+func jsonProperties(props []jsonProperty) []rawProperty {
+	var raw []rawProperty
+	for _, p := range props {
+		raw = append(raw, rawProperty{p.Name, fmt.Sprint(p.Value)})
+	}
+	return raw
+}
+
+func readJSONTileset(ts jsonTileset) rawTileset {
+	raw := rawTileset{
+		FirstGID:   ts.FirstGID,
+		Name:       ts.Name,
+		TileWidth:  ts.TileWidth,
+		TileHeight: ts.TileHeight,
+		Margin:     ts.Margin,
+		Spacing:    ts.Spacing,
+		Columns:    ts.Columns,
+		TileCount:  ts.TileCount,
+		Image:      ts.Image,
+	}
+	for _, t := range ts.Tiles {
+		raw.Tiles = append(raw.Tiles, rawTile{t.ID, jsonProperties(t.Properties)})
+	}
+	return raw
+}
+
+func readTiledJSON(path string) (*rawMap, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jm jsonMap
+	if err := json.Unmarshal(b, &jm); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	rm := &rawMap{
+		Orientation: jm.Orientation,
+		Width:       jm.Width,
+		Height:      jm.Height,
+		TileWidth:   jm.TileWidth,
+		TileHeight:  jm.TileHeight,
+		Properties:  jsonProperties(jm.Properties),
+	}
+
+	for _, ts := range jm.Tilesets {
+		// External tilesets live in their own JSON file
+		if ts.Source != "" {
+			tsPath := filepath.Join(filepath.Dir(path), ts.Source)
+			b, err := os.ReadFile(tsPath)
+			if err != nil {
+				return nil, err
+			}
+			var ext jsonTileset
+			if err := json.Unmarshal(b, &ext); err != nil {
+				return nil, fmt.Errorf("%s: %v", tsPath, err)
+			}
+			ext.FirstGID = ts.FirstGID
+			ext.Image = filepath.Join(filepath.Dir(ts.Source), ext.Image)
+			ts = ext
+		}
+		rm.Tilesets = append(rm.Tilesets, readJSONTileset(ts))
+	}
+
+	for _, l := range jm.Layers {
+		rl := rawLayer{
+			Name:       l.Name,
+			Width:      l.Width,
+			Height:     l.Height,
+			Visible:    l.Visible == nil || *l.Visible,
+			Properties: jsonProperties(l.Properties),
+		}
+		switch l.Type {
+		case "tilelayer":
+			if l.Encoding == "base64" {
+				var text string
+				if err := json.Unmarshal(l.Data, &text); err != nil {
+					return nil, fmt.Errorf("%s: layer %q: %v", path, l.Name, err)
+				}
+				rl.Data, err = decodeTileData(l.Encoding, l.Compression, text)
+			} else {
+				err = json.Unmarshal(l.Data, &rl.Data)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: layer %q: %v", path, l.Name, err)
+			}
+		case "objectgroup":
+			rl.Objects = true
+			for _, o := range l.Objects {
+				typ := o.Type
+				if typ == "" {
+					typ = o.Class
+				}
+				rl.ObjectList = append(rl.ObjectList, rawObject{
+					o.ID, o.Name, typ, o.X, o.Y, o.Width, o.Height, jsonProperties(o.Properties),
+				})
+			}
+		default:
+			continue
+		}
+		rm.Layers = append(rm.Layers, rl)
+	}
+
+	return rm, nil
+}
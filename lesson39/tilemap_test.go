@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Both fixtures describe the same 3x2 map with two tilesets, a visible
+// ground layer, an invisible solid overlay and an object layer
+
+var groundData = []uint32{1, 4, 5, 8 | FLIPPED_HORIZONTALLY, 6 | FLIPPED_VERTICALLY | FLIPPED_DIAGONALLY, 0}
+
+var overlayData = []uint32{0, 0, 0, 0, 0, 7 | FLIPPED_HORIZONTALLY | FLIPPED_VERTICALLY | FLIPPED_DIAGONALLY}
+
+const tmxFixture = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="3" height="2" tilewidth="32" tileheight="32">
+ <properties>
+  <property name="title" value="Fixture"/>
+  <property name="notes">first line
+second line</property>
+ </properties>
+ <tileset firstgid="1" name="ground" tilewidth="32" tileheight="32" tilecount="4" columns="2">
+  <image source="ground.png" width="64" height="64"/>
+  <tile id="2">
+   <properties>
+    <property name="solid" type="bool" value="true"/>
+   </properties>
+  </tile>
+ </tileset>
+ <tileset firstgid="5" name="props" tilewidth="32" tileheight="64" margin="1" spacing="2" tilecount="4" columns="4">
+  <image source="props.png" width="135" height="66"/>
+ </tileset>
+ <layer id="1" name="ground" width="3" height="2">
+  <data encoding="csv">
+%s
+</data>
+ </layer>
+ <layer id="2" name="overlay" width="3" height="2" visible="0">
+  <properties>
+   <property name="solid" type="bool" value="true"/>
+  </properties>
+  <data encoding="base64" compression="zlib">%s</data>
+ </layer>
+ <objectgroup id="3" name="objects">
+  <object id="1" name="spawn" class="player" x="48" y="16.5" width="16" height="16">
+   <properties>
+    <property name="facing" value="left"/>
+   </properties>
+  </object>
+  <object id="2" name="exit" type="door" x="80" y="32" width="16" height="32"/>
+ </objectgroup>
+</map>
+`
+
+const jsonFixture = `{
+ "orientation": "orthogonal", "width": 3, "height": 2, "tilewidth": 32, "tileheight": 32,
+ "properties": [
+  {"name": "title", "type": "string", "value": "Fixture"},
+  {"name": "notes", "type": "string", "value": "first line\nsecond line"}
+ ],
+ "tilesets": [
+  {"firstgid": 1, "name": "ground", "tilewidth": 32, "tileheight": 32, "tilecount": 4, "columns": 2,
+   "image": "ground.png",
+   "tiles": [{"id": 2, "properties": [{"name": "solid", "type": "bool", "value": true}]}]},
+  {"firstgid": 5, "name": "props", "tilewidth": 32, "tileheight": 64, "margin": 1, "spacing": 2,
+   "tilecount": 4, "columns": 4, "image": "props.png"}
+ ],
+ "layers": [
+  {"type": "tilelayer", "name": "ground", "width": 3, "height": 2, "visible": true, "data": [%s]},
+  {"type": "tilelayer", "name": "overlay", "width": 3, "height": 2, "visible": false,
+   "encoding": "base64", "compression": "gzip", "data": "%s",
+   "properties": [{"name": "solid", "type": "bool", "value": true}]},
+  {"type": "objectgroup", "name": "objects", "objects": [
+   {"id": 1, "name": "spawn", "class": "player", "x": 48, "y": 16.5, "width": 16, "height": 16,
+    "properties": [{"name": "facing", "type": "string", "value": "left"}]},
+   {"id": 2, "name": "exit", "type": "door", "x": 80, "y": 32, "width": 16, "height": 32}
+  ]}
+ ]
+}`
+
+func csv(data []uint32) string {
+	fields := make([]string, len(data))
+	for i, v := range data {
+		fields[i] = fmt.Sprint(v)
+	}
+	return strings.Join(fields, ",")
+}
+
+// Tile IDs as Tiled stores them in base64: little endian, then compressed
+func encodeTileData(t *testing.T, data []uint32, compression string) string {
+	raw := make([]byte, 4*len(data))
+	for i, v := range data {
+		binary.LittleEndian.PutUint32(raw[i*4:], v)
+	}
+
+	var buf bytes.Buffer
+	switch compression {
+	case "":
+		buf.Write(raw)
+	case "zlib":
+		w := zlib.NewWriter(&buf)
+		w.Write(raw)
+		w.Close()
+	case "gzip":
+		w := gzip.NewWriter(&buf)
+		w.Write(raw)
+		w.Close()
+	default:
+		t.Fatalf("unknown compression %q", compression)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func writeFixture(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func checkRawMap(t *testing.T, rm *rawMap) {
+	t.Helper()
+
+	if rm.Width != 3 || rm.Height != 2 || rm.TileWidth != 32 || rm.TileHeight != 32 {
+		t.Errorf("map is %dx%d tiles of %dx%d, want 3x2 of 32x32", rm.Width, rm.Height, rm.TileWidth, rm.TileHeight)
+	}
+	props := toProperties(rm.Properties)
+	if props["title"] != "Fixture" || props["notes"] != "first line\nsecond line" {
+		t.Errorf("map properties = %q", props)
+	}
+
+	if len(rm.Tilesets) != 2 {
+		t.Fatalf("got %d tilesets, want 2", len(rm.Tilesets))
+	}
+	ground, decor := rm.Tilesets[0], rm.Tilesets[1]
+	if ground.FirstGID != 1 || ground.Columns != 2 || ground.TileCount != 4 || ground.Image != "ground.png" {
+		t.Errorf("first tileset = %+v", ground)
+	}
+	if len(ground.Tiles) != 1 || ground.Tiles[0].ID != 2 || !toProperties(ground.Tiles[0].Properties).bool("solid") {
+		t.Errorf("first tileset tiles = %+v", ground.Tiles)
+	}
+	if decor.FirstGID != 5 || decor.TileHeight != 64 || decor.Margin != 1 || decor.Spacing != 2 || decor.Image != "props.png" {
+		t.Errorf("second tileset = %+v", decor)
+	}
+
+	if len(rm.Layers) != 3 {
+		t.Fatalf("got %d layers, want 3", len(rm.Layers))
+	}
+	groundLayer, overlay, objects := rm.Layers[0], rm.Layers[1], rm.Layers[2]
+	if groundLayer.Name != "ground" || !groundLayer.Visible || groundLayer.Objects {
+		t.Errorf("ground layer = %+v", groundLayer)
+	}
+	if !reflect.DeepEqual(groundLayer.Data, groundData) {
+		t.Errorf("ground data = %v, want %v", groundLayer.Data, groundData)
+	}
+	if overlay.Visible || !toProperties(overlay.Properties).bool("solid") {
+		t.Errorf("overlay layer = %+v", overlay)
+	}
+	if !reflect.DeepEqual(overlay.Data, overlayData) {
+		t.Errorf("overlay data = %v, want %v", overlay.Data, overlayData)
+	}
+
+	if !objects.Objects || len(objects.ObjectList) != 2 {
+		t.Fatalf("object layer = %+v", objects)
+	}
+	spawn, exit := objects.ObjectList[0], objects.ObjectList[1]
+	if spawn.ID != 1 || spawn.Name != "spawn" || spawn.Type != "player" ||
+		spawn.X != 48 || spawn.Y != 16.5 || spawn.W != 16 || spawn.H != 16 {
+		t.Errorf("spawn object = %+v", spawn)
+	}
+	if toProperties(spawn.Properties)["facing"] != "left" {
+		t.Errorf("spawn properties = %+v", spawn.Properties)
+	}
+	if exit.Name != "exit" || exit.Type != "door" || exit.H != 32 {
+		t.Errorf("exit object = %+v", exit)
+	}
+}
+
+func TestReadTMX(t *testing.T) {
+	path := writeFixture(t, "fixture.tmx", fmt.Sprintf(tmxFixture, csv(groundData), encodeTileData(t, overlayData, "zlib")))
+	rm, err := readTMX(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRawMap(t, rm)
+}
+
+func TestReadTiledJSON(t *testing.T) {
+	path := writeFixture(t, "fixture.json", fmt.Sprintf(jsonFixture, csv(groundData), encodeTileData(t, overlayData, "gzip")))
+	rm, err := readTiledJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRawMap(t, rm)
+}
+
+func TestDecodeTileData(t *testing.T) {
+	for _, compression := range []string{"", "zlib", "gzip"} {
+		data, err := decodeTileData("base64", compression, encodeTileData(t, groundData, compression))
+		if err != nil || !reflect.DeepEqual(data, groundData) {
+			t.Errorf("base64 %q: got %v, %v", compression, data, err)
+		}
+	}
+
+	data, err := decodeTileData("csv", "", "\n"+csv(groundData)+",\n")
+	if err != nil || !reflect.DeepEqual(data, groundData) {
+		t.Errorf("csv: got %v, %v", data, err)
+	}
+
+	// A bad checksum in the trailer fails, as does a cut off stream
+	for _, compression := range []string{"zlib", "gzip"} {
+		b, err := base64.StdEncoding.DecodeString(encodeTileData(t, groundData, compression))
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrupt := append([]byte(nil), b...)
+		corrupt[len(corrupt)-5] ^= 0xff
+		if compression == "zlib" {
+			corrupt[len(corrupt)-1] ^= 0xff
+		}
+		if _, err := decodeTileData("base64", compression, base64.StdEncoding.EncodeToString(corrupt)); err == nil {
+			t.Errorf("%s with a bad checksum decoded", compression)
+		}
+		if _, err := decodeTileData("base64", compression, base64.StdEncoding.EncodeToString(b[:len(b)-3])); err == nil {
+			t.Errorf("truncated %s decoded", compression)
+		}
+	}
+
+	if _, err := decodeTileData("base64", "zstd", ""); err == nil {
+		t.Error("unknown compression decoded")
+	}
+	if _, err := decodeTileData("xml", "", ""); err == nil {
+		t.Error("unknown encoding decoded")
+	}
+}
+
+func TestTilesetFor(t *testing.T) {
+	m := &tileMap{tilesets: []*tileset{{firstGID: 1}, {firstGID: 5}, {firstGID: 9}}}
+
+	for _, c := range []struct {
+		gid  int
+		want int
+	}{{0, -1}, {1, 0}, {4, 0}, {5, 1}, {8, 1}, {9, 2}, {100, 2}} {
+		got := m.tilesetFor(c.gid)
+		if c.want < 0 {
+			if got != nil {
+				t.Errorf("gid %d: got tileset from %d, want none", c.gid, got.firstGID)
+			}
+			continue
+		}
+		if got != m.tilesets[c.want] {
+			t.Errorf("gid %d: got %v, want the tileset from %d", c.gid, got, m.tilesets[c.want].firstGID)
+		}
+	}
+}
+
+func TestTileFlip(t *testing.T) {
+	for _, c := range []struct {
+		flags uint32
+		angle float64
+		flip  sdl.RendererFlip
+	}{
+		{0, 0, sdl.FLIP_NONE},
+		{FLIPPED_HORIZONTALLY, 0, sdl.FLIP_HORIZONTAL},
+		{FLIPPED_VERTICALLY, 0, sdl.FLIP_VERTICAL},
+		{FLIPPED_HORIZONTALLY | FLIPPED_VERTICALLY, 0, sdl.FLIP_HORIZONTAL | sdl.FLIP_VERTICAL},
+
+		// Transposed: flipped vertically, then turned clockwise
+		{FLIPPED_DIAGONALLY, 90, sdl.FLIP_VERTICAL},
+
+		// Tiled's clockwise turn
+		{FLIPPED_DIAGONALLY | FLIPPED_HORIZONTALLY, 90, sdl.FLIP_NONE},
+
+		// Tiled's counterclockwise turn
+		{FLIPPED_DIAGONALLY | FLIPPED_VERTICALLY, 90, sdl.FLIP_HORIZONTAL | sdl.FLIP_VERTICAL},
+
+		{FLIPPED_DIAGONALLY | FLIPPED_HORIZONTALLY | FLIPPED_VERTICALLY, 90, sdl.FLIP_HORIZONTAL},
+	} {
+		angle, flip := tileFlip(c.flags | 3)
+		if angle != c.angle || flip != c.flip {
+			t.Errorf("flags %#x: got %v, %v, want %v, %v", c.flags, angle, flip, c.angle, c.flip)
+		}
+	}
+}
+
+func TestTouchesSolid(t *testing.T) {
+	// An empty 4x4 map; only what is outside of it is solid
+	m := &tileMap{width: 4, height: 4, tileWidth: 32, tileHeight: 32}
+
+	for _, c := range []struct {
+		box  sdl.Rect
+		want bool
+	}{
+		{sdl.Rect{0, 0, 20, 20}, false},
+		{sdl.Rect{108, 108, 20, 20}, false},
+		{sdl.Rect{-1, 40, 20, 20}, true},
+		{sdl.Rect{40, -1, 20, 20}, true},
+		{sdl.Rect{109, 40, 20, 20}, true},
+		{sdl.Rect{40, 109, 20, 20}, true},
+	} {
+		if got := m.touchesSolid(&c.box); got != c.want {
+			t.Errorf("box %v: got %v, want %v", c.box, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"encoding/binary"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"math"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+// Pixel format of all streaming textures, one uint32 per pixel
+const STREAMING_FORMAT = sdl.PIXELFORMAT_ARGB8888
+
+// Size of the procedural background; it is stretched over the screen
+const (
+	PLASMA_WIDTH  = SCREEN_WIDTH / 4
+	PLASMA_HEIGHT = SCREEN_HEIGHT / 4
+)
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gFooTexture *MyTexture
+var gPlasmaTexture *MyTexture
+
+// Colors the figure cycles through on key press
+var gPalette = []sdl.Color{
+	{0, 0, 0, 255},
+	{200, 30, 30, 255},
+	{30, 160, 30, 255},
+	{40, 60, 220, 255},
+}
+
+/* ------------------------------ pixel format helpers ------------------------------ */
+
+// Details of STREAMING_FORMAT, needed to pack and unpack colors
+var gStreamingFormat *sdl.PixelFormat
+
+func mapColor(c sdl.Color) uint32 {
+	return sdl.MapRGBA(gStreamingFormat, c.R, c.G, c.B, c.A)
+}
+
+func unmapColor(pixel uint32) sdl.Color {
+	r, g, b, a := sdl.GetRGBA(pixel, gStreamingFormat)
+	return sdl.Color{r, g, b, a}
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+
+	// Copy of the pixels of a streaming texture. Locked pixels of a
+	// streaming texture are write only, so reads are served from here.
+	pixels []uint32
+	locked bool
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+// Loads an image into a texture whose pixels can be changed afterwards
+func NewStreamingMyTexture(renderer *sdl.Renderer, path string) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadStreamingFromFile(path)
+	return t
+}
+
+// Creates an empty streaming texture, e.g. for procedural images
+func NewBlankStreamingMyTexture(renderer *sdl.Renderer, width, height int32) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.createBlank(width, height)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+	t.pixels = nil
+	t.locked = false
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) loadStreamingFromFile(path string) {
+	// Free pre-existing texture
+	t.free()
+
+	loadedSurface, err := img.Load(path)
+	must(err)
+
+	// Convert the surface to the streaming format so it can be copied as is
+	surface, err := loadedSurface.ConvertFormat(STREAMING_FORMAT, 0)
+	must(err)
+	loadedSurface.Free()
+
+	t.createBlank(surface.W, surface.H)
+
+	// Copy the surface row by row, its pitch may include padding
+	t.lock()
+	src := surface.Pixels()
+	for y := 0; y < int(t.height); y++ {
+		row := src[y*int(surface.Pitch):]
+		for x := 0; x < int(t.width); x++ {
+			t.pixels[y*int(t.width)+x] = binary.NativeEndian.Uint32(row[x*4:])
+		}
+	}
+	t.unlock()
+
+	// Free converted surface
+	surface.Free()
+}
+
+func (t *MyTexture) createBlank(width, height int32) {
+	// Free pre-existing texture
+	t.free()
+
+	var err error
+	t.texture, err = t.renderer.CreateTexture(STREAMING_FORMAT, sdl.TEXTUREACCESS_STREAMING, int(width), int(height))
+	must(err)
+
+	t.width = width
+	t.height = height
+	t.pixels = make([]uint32, width*height)
+
+	// Streaming textures have alpha, so blend by default
+	t.setBlendMode(sdl.BLENDMODE_BLEND)
+}
+
+// Starts editing the pixels of a streaming texture
+func (t *MyTexture) lock() {
+	if t.pixels == nil {
+		panic("not a streaming texture")
+	}
+	if t.locked {
+		panic("texture is already locked")
+	}
+	t.locked = true
+}
+
+// Uploads the edited pixels to the texture
+func (t *MyTexture) unlock() {
+	if !t.locked {
+		panic("texture is not locked")
+	}
+
+	buf, pitch, err := t.texture.Lock(nil)
+	must(err)
+	for y := 0; y < int(t.height); y++ {
+		row := buf[y*pitch:]
+		for x := 0; x < int(t.width); x++ {
+			binary.NativeEndian.PutUint32(row[x*4:], t.pixels[y*int(t.width)+x])
+		}
+	}
+	t.texture.Unlock()
+
+	t.locked = false
+}
+
+func (t *MyTexture) getPixel(x, y int32) sdl.Color {
+	return unmapColor(t.pixels[y*t.width+x])
+}
+
+// Changes a single pixel; the texture must be locked
+func (t *MyTexture) setPixel(x, y int32, c sdl.Color) {
+	if !t.locked {
+		panic("texture is not locked")
+	}
+	t.pixels[y*t.width+x] = mapColor(c)
+}
+
+// Replaces every pixel with what f returns for it; the texture must be locked
+func (t *MyTexture) mapPixels(f func(x, y int32, c sdl.Color) sdl.Color) {
+	for y := int32(0); y < t.height; y++ {
+		for x := int32(0); x < t.width; x++ {
+			t.setPixel(x, y, f(x, y, t.getPixel(x, y)))
+		}
+	}
+}
+
+// Makes all pixels of the key color transparent, like a color key would
+func (t *MyTexture) replaceColorKey(key sdl.Color) {
+	t.mapPixels(func(x, y int32, c sdl.Color) sdl.Color {
+		if c.R == key.R && c.G == key.G && c.B == key.B {
+			return sdl.Color{c.R, c.G, c.B, 0}
+		}
+		return c
+	})
+}
+
+// Swaps one color for another, keeping the alpha of each pixel
+func (t *MyTexture) recolor(from, to sdl.Color) {
+	t.mapPixels(func(x, y int32, c sdl.Color) sdl.Color {
+		if c.R == from.R && c.G == from.G && c.B == from.B {
+			return sdl.Color{to.R, to.G, to.B, c.A}
+		}
+		return c
+	})
+}
+
+// Scales the alpha of each pixel by what alpha returns for it
+func (t *MyTexture) applyAlpha(alpha func(x, y int32) uint8) {
+	t.mapPixels(func(x, y int32, c sdl.Color) sdl.Color {
+		c.A = uint8(uint32(c.A) * uint32(alpha(x, y)) / 255)
+		return c
+	})
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gStreamingFormat, err = sdl.AllocFormat(STREAMING_FORMAT)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gFooTexture = NewStreamingMyTexture(gRenderer, "assets/foo.png")
+
+	// Turn the cyan background transparent
+	gFooTexture.lock()
+	gFooTexture.replaceColorKey(sdl.Color{0, 0xFF, 0xFF, 0xFF})
+	gFooTexture.unlock()
+
+	gPlasmaTexture = NewBlankStreamingMyTexture(gRenderer, PLASMA_WIDTH, PLASMA_HEIGHT)
+}
+
+func close() {
+	gFooTexture.free()
+	gPlasmaTexture.free()
+
+	gStreamingFormat.Free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Redraws the plasma for the given time in seconds
+func updatePlasma(time float64) {
+	gPlasmaTexture.lock()
+	for y := int32(0); y < PLASMA_HEIGHT; y++ {
+		for x := int32(0); x < PLASMA_WIDTH; x++ {
+			fx := float64(x) / 16
+			fy := float64(y) / 16
+			v := math.Sin(fx+time) + math.Sin((fy+time)/2) + math.Sin((fx+fy+time)/2) +
+				math.Sin(math.Hypot(fx-5, fy-4)+time)
+
+			// Map v from [-4, 4] to three phase-shifted channels
+			gPlasmaTexture.setPixel(x, y, sdl.Color{
+				uint8(128 + 127*math.Sin(v*math.Pi/4)),
+				uint8(128 + 127*math.Sin(v*math.Pi/4+2*math.Pi/3)),
+				uint8(128 + 127*math.Sin(v*math.Pi/4+4*math.Pi/3)),
+				255,
+			})
+		}
+	}
+	gPlasmaTexture.unlock()
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	// Current color of the figure
+	var paletteIndex int
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_C:
+					// Palette swap to the next color
+					next := (paletteIndex + 1) % len(gPalette)
+					gFooTexture.lock()
+					gFooTexture.recolor(gPalette[paletteIndex], gPalette[next])
+					gFooTexture.unlock()
+					paletteIndex = next
+				case sdl.SCANCODE_A:
+					// Fade the figure out from top to bottom
+					gFooTexture.lock()
+					gFooTexture.applyAlpha(func(x, y int32) uint8 {
+						return uint8(255 - 255*y/gFooTexture.height)
+					})
+					gFooTexture.unlock()
+				case sdl.SCANCODE_R:
+					// Reload the original image
+					gFooTexture.loadStreamingFromFile("assets/foo.png")
+					gFooTexture.lock()
+					gFooTexture.replaceColorKey(sdl.Color{0, 0xFF, 0xFF, 0xFF})
+					gFooTexture.unlock()
+					paletteIndex = 0
+				}
+			}
+		}
+
+		updatePlasma(float64(sdl.GetTicks()) / 1000)
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// Render the plasma stretched over the screen, and the figure on top
+		gRenderer.Copy(gPlasmaTexture.texture, nil, nil)
+		gFooTexture.render((SCREEN_WIDTH-gFooTexture.width)/2, (SCREEN_HEIGHT-gFooTexture.height)/2, nil)
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
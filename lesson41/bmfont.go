@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type bitmapGlyph struct {
+	// Page the glyph is on and where
+	page int
+	clip sdl.Rect
+
+	// Where to draw the clip relative to the pen position
+	xOffset, yOffset int32
+
+	// How far to move the pen afterwards
+	xAdvance int32
+}
+
+type kerningPair struct {
+	first, second rune
+}
+
+type bitmapFont struct {
+	pages   []*MyTexture
+	glyphs  map[rune]*bitmapGlyph
+	kerning map[kerningPair]int32
+
+	// Distance between lines, and from the top of a line to the baseline
+	lineHeight int32
+	base       int32
+
+	// Whether kerning pairs are applied
+	useKerning bool
+}
+
+// Pages are added as they load, so this also cleans up after a failed load
+func (f *bitmapFont) free() {
+	for _, p := range f.pages {
+		p.free()
+	}
+}
+
+func (f *bitmapFont) setColor(r, g, b uint8) {
+	for _, p := range f.pages {
+		p.setColor(r, g, b)
+	}
+}
+
+// Walks through the text like renderText does, calling draw for every glyph
+func (f *bitmapFont) layout(x, y int32, text string, draw func(g *bitmapGlyph, x, y int32)) (width, height int32) {
+	penX, penY := x, y
+	var prev rune
+	for _, r := range text {
+		if r == '\n' {
+			penX = x
+			penY += f.lineHeight
+			prev = 0
+			continue
+		}
+
+		// Missing characters are drawn, and kerned, as '?'
+		g := f.glyphs[r]
+		if g == nil {
+			r = '?'
+			g = f.glyphs[r]
+			if g == nil {
+				continue
+			}
+		}
+		if f.useKerning && prev != 0 {
+			penX += f.kerning[kerningPair{prev, r}]
+		}
+		if draw != nil {
+			draw(g, penX+g.xOffset, penY+g.yOffset)
+		}
+		penX += g.xAdvance
+		prev = r
+
+		if penX-x > width {
+			width = penX - x
+		}
+	}
+	return width, penY - y + f.lineHeight
+}
+
+// Renders text with its top left corner at x, y
+func (f *bitmapFont) renderText(x, y int32, text string) {
+	f.layout(x, y, text, func(g *bitmapGlyph, x, y int32) {
+		f.pages[g.page].render(x, y, &g.clip)
+	})
+}
+
+// Size of the text when rendered
+func (f *bitmapFont) measure(text string) (width, height int32) {
+	return f.layout(0, 0, text, nil)
+}
+
+/* ------------------------------ AngelCode BMFont ------------------------------ */
+
+// A parsed "char", "kerning" etc. line or XML element
+type bmTag struct {
+	name  string
+	attrs map[string]string
+}
+
+func (t bmTag) int(key string) int32 {
+	v, _ := strconv.Atoi(t.attrs[key])
+	return int32(v)
+}
+
+// Loads a BMFont descriptor in the text or the XML format. Page images
+// are looked up next to the descriptor.
+func loadBMFont(renderer *sdl.Renderer, path string) (*bitmapFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, pageFiles, err := parseBMFont(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	for id, file := range pageFiles {
+		page := &MyTexture{renderer: renderer}
+		if err := page.reloadFromFile(filepath.Join(filepath.Dir(path), file), nil); err != nil {
+			f.free()
+			return nil, fmt.Errorf("%s: page %d: %v", path, id, err)
+		}
+		f.pages = append(f.pages, page)
+	}
+	return f, nil
+}
+
+// Parses a descriptor into a font without pages, and the file names of the
+// page images in page order
+func parseBMFont(data []byte) (*bitmapFont, []string, error) {
+	var tags []bmTag
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		tags, err = parseBMFontXML(data)
+	} else {
+		tags, err = parseBMFontText(data)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f := &bitmapFont{
+		glyphs:     map[rune]*bitmapGlyph{},
+		kerning:    map[kerningPair]int32{},
+		useKerning: true,
+	}
+	pageCount := 0
+	pageFiles := map[int]string{}
+	for _, t := range tags {
+		switch t.name {
+		case "common":
+			f.lineHeight = t.int("lineHeight")
+			f.base = t.int("base")
+			pageCount = int(t.int("pages"))
+		case "page":
+			pageFiles[int(t.int("id"))] = t.attrs["file"]
+		case "char":
+			f.glyphs[rune(t.int("id"))] = &bitmapGlyph{
+				page:     int(t.int("page")),
+				clip:     sdl.Rect{t.int("x"), t.int("y"), t.int("width"), t.int("height")},
+				xOffset:  t.int("xoffset"),
+				yOffset:  t.int("yoffset"),
+				xAdvance: t.int("xadvance"),
+			}
+		case "kerning":
+			f.kerning[kerningPair{rune(t.int("first")), rune(t.int("second"))}] = t.int("amount")
+		}
+	}
+
+	// Every page needs its own page line, which also keeps a broken count
+	// from allocating much
+	if pageCount < 0 || pageCount > len(pageFiles) {
+		return nil, nil, fmt.Errorf("%d pages declared, %d listed", pageCount, len(pageFiles))
+	}
+	files := make([]string, pageCount)
+	for id := range files {
+		file, ok := pageFiles[id]
+		if !ok {
+			return nil, nil, fmt.Errorf("missing page %d", id)
+		}
+		files[id] = file
+	}
+	for r, g := range f.glyphs {
+		if g.page < 0 || g.page >= pageCount {
+			return nil, nil, fmt.Errorf("char %d is on unknown page %d", r, g.page)
+		}
+	}
+
+	return f, files, nil
+}
+
+// Parses lines like: char id=65 x=10 y=0 width=12 ... or page id=0 file="a.png"
+func parseBMFontText(data []byte) ([]bmTag, error) {
+	var tags []bmTag
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		end := strings.IndexByte(line, ' ')
+		if end < 0 {
+			end = len(line)
+		}
+		t := bmTag{name: line[:end], attrs: map[string]string{}}
+		rest := line[end:]
+
+		for {
+			rest = strings.TrimLeft(rest, " \t")
+			if rest == "" {
+				break
+			}
+			eq := strings.IndexByte(rest, '=')
+			if eq < 0 {
+				return nil, fmt.Errorf("line %d: expected key=value", lineNo)
+			}
+			key := rest[:eq]
+			rest = rest[eq+1:]
+
+			var value string
+			if strings.HasPrefix(rest, `"`) {
+				closing := strings.IndexByte(rest[1:], '"')
+				if closing < 0 {
+					return nil, fmt.Errorf("line %d: unterminated string", lineNo)
+				}
+				value = rest[1 : closing+1]
+				rest = rest[closing+2:]
+			} else {
+				space := strings.IndexAny(rest, " \t")
+				if space < 0 {
+					space = len(rest)
+				}
+				value = rest[:space]
+				rest = rest[space:]
+			}
+			t.attrs[key] = value
+		}
+		tags = append(tags, t)
+	}
+	return tags, scanner.Err()
+}
+
+// Flattens the XML variant into the same tags as the text variant
+func parseBMFontXML(data []byte) ([]bmTag, error) {
+	var tags []bmTag
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return tags, nil
+			}
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		t := bmTag{name: start.Name.Local, attrs: map[string]string{}}
+		for _, a := range start.Attr {
+			t.attrs[a.Name.Local] = a.Value
+		}
+		tags = append(tags, t)
+	}
+}
+
+/* ------------------------------ fixed grid sheets ------------------------------ */
+
+// Loads a sheet where the characters from firstChar on are laid out row by
+// row in equally sized cells, every character being as wide as its cell.
+func loadGridFont(renderer *sdl.Renderer, path string, columns, rows int32, firstChar rune) *bitmapFont {
+	page := NewMyTexture(renderer, path, nil)
+	cellWidth := page.width / columns
+	cellHeight := page.height / rows
+
+	f := &bitmapFont{
+		pages:      []*MyTexture{page},
+		glyphs:     map[rune]*bitmapGlyph{},
+		kerning:    map[kerningPair]int32{},
+		lineHeight: cellHeight,
+		base:       cellHeight,
+	}
+	for i := int32(0); i < columns*rows; i++ {
+		f.glyphs[firstChar+rune(i)] = &bitmapGlyph{
+			clip:     sdl.Rect{(i % columns) * cellWidth, (i / columns) * cellHeight, cellWidth, cellHeight},
+			xAdvance: cellWidth,
+		}
+	}
+	return f
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testFont = `info face="Test Face" size=16 unicode=1
+common lineHeight=20 base=16 scaleW=64 scaleH=64 pages=2
+page id=0 file="test_0.png"
+page id=1 file="page one.png"
+chars count=3
+char id=65   x=0    y=0    width=10   height=12   xoffset=1   yoffset=2   xadvance=11   page=0  chnl=15
+char id=86   x=10   y=0    width=10   height=12   xoffset=0   yoffset=2   xadvance=10   page=1  chnl=15
+char id=63   x=20   y=0    width=8    height=12   xoffset=1   yoffset=2   xadvance=9    page=0  chnl=15
+kernings count=2
+kerning first=65  second=86  amount=-3
+kerning first=63  second=65  amount=-1
+`
+
+func TestParseBMFontText(t *testing.T) {
+	f, pages, err := parseBMFont([]byte(testFont))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"test_0.png", "page one.png"}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("pages: got %q, want %q", pages, want)
+	}
+	if f.lineHeight != 20 || f.base != 16 {
+		t.Errorf("line height %d, base %d, want 20, 16", f.lineHeight, f.base)
+	}
+
+	if len(f.glyphs) != 3 {
+		t.Errorf("%d glyphs, want 3", len(f.glyphs))
+	}
+	want := bitmapGlyph{page: 1, clip: sdl.Rect{10, 0, 10, 12}, xOffset: 0, yOffset: 2, xAdvance: 10}
+	if g := f.glyphs['V']; g == nil || *g != want {
+		t.Errorf("glyph V: got %+v, want %+v", g, want)
+	}
+
+	if len(f.kerning) != 2 || f.kerning[kerningPair{'A', 'V'}] != -3 || f.kerning[kerningPair{'?', 'A'}] != -1 {
+		t.Errorf("kerning: got %v", f.kerning)
+	}
+}
+
+func TestMeasureKerning(t *testing.T) {
+	f, _, err := parseBMFont([]byte(testFont))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		text    string
+		kerning bool
+		width   int32
+		height  int32
+	}{
+		{"AV", true, 11 - 3 + 10, 20},
+		{"AV", false, 11 + 10, 20},
+
+		// Missing characters are '?', and kerned as such
+		{"xA", true, 9 - 1 + 11, 20},
+		{"A\nV", true, 11, 40},
+	} {
+		f.useKerning = c.kerning
+		if w, h := f.measure(c.text); w != c.width || h != c.height {
+			t.Errorf("%q, kerning %v: got %dx%d, want %dx%d", c.text, c.kerning, w, h, c.width, c.height)
+		}
+	}
+}
+
+func TestParseBMFontRejects(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		old, new string
+	}{
+		{"more pages than listed", "pages=2", "pages=3"},
+		{"negative page count", "pages=2", "pages=-1"},
+		{"huge page count", "pages=2", "pages=2000000000"},
+		{"missing page", "page id=1", "page id=5"},
+		{"char on unknown page", "page=1", "page=2"},
+		{"unterminated string", `"page one.png"`, `"page one.png`},
+		{"attribute without value", "chnl=15\nchar id=86", "chnl\nchar id=86"},
+	} {
+		data := strings.Replace(testFont, c.old, c.new, 1)
+		if data == testFont {
+			t.Fatalf("%s: %q not in the font", c.name, c.old)
+		}
+		if _, _, err := parseBMFont([]byte(data)); err == nil {
+			t.Errorf("%s: parsed", c.name)
+		}
+	}
+}
+
+// Both formats of the font the lesson ships describe the same font
+func TestParseBMFontXMLMatchesText(t *testing.T) {
+	parse := func(path string) (*bitmapFont, []string) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, pages, err := parseBMFont(data)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		return f, pages
+	}
+	text, textPages := parse("assets/font.fnt")
+	xml, xmlPages := parse("assets/font_xml.fnt")
+
+	if !reflect.DeepEqual(textPages, xmlPages) {
+		t.Errorf("pages: text %q, XML %q", textPages, xmlPages)
+	}
+	if !reflect.DeepEqual(text.glyphs, xml.glyphs) {
+		t.Error("glyphs differ")
+	}
+	if !reflect.DeepEqual(text.kerning, xml.kerning) {
+		t.Errorf("kerning: text %v, XML %v", text.kerning, xml.kerning)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+// Shown with the BMFont, the kerning pairs are easy to spot in it
+const SAMPLE_TEXT = "AVA WAVE, To Tea.\nLAST Fall: Yoyo.\nPress K to toggle kerning,\nX to switch to the XML file."
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gBMFont *bitmapFont
+var gGridFont *bitmapFont
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	must(t.reloadFromFile(path, colorKey))
+}
+
+// Like loadFromFile, but returns the error and keeps the current texture if
+// the file can't be loaded
+func (t *MyTexture) reloadFromFile(path string, colorKey *sdl.Color) error {
+	surface, err := img.Load(path)
+	if err != nil {
+		return err
+	}
+	defer surface.Free()
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	texture, err := t.renderer.CreateTextureFromSurface(surface)
+	if err != nil {
+		return err
+	}
+
+	// Free pre-existing texture
+	t.free()
+	t.texture = texture
+	t.width = surface.W
+	t.height = surface.H
+	return nil
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	var err error
+	gBMFont, err = loadBMFont(gRenderer, "assets/font.fnt")
+	must(err)
+
+	// 16 columns and 6 rows from the space character on
+	gGridFont = loadGridFont(gRenderer, "assets/grid.png", 16, 6, ' ')
+}
+
+func close() {
+	gBMFont.free()
+	gGridFont.free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	// Which of the two descriptors of the same font is loaded
+	var xmlLoaded bool
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_K:
+					gBMFont.useKerning = !gBMFont.useKerning
+				case sdl.SCANCODE_X:
+					path := "assets/font.fnt"
+					if !xmlLoaded {
+						path = "assets/font_xml.fnt"
+					}
+					font, err := loadBMFont(gRenderer, path)
+					must(err)
+					font.useKerning = gBMFont.useKerning
+					gBMFont.free()
+					gBMFont = font
+					xmlLoaded = !xmlLoaded
+				}
+			}
+		}
+
+		// Clear screen
+		gRenderer.SetDrawColor(0, 0, 0, 255)
+		gRenderer.Clear()
+
+		// Render the BMFont text centered
+		w, h := gBMFont.measure(SAMPLE_TEXT)
+		gBMFont.setColor(255, 220, 120)
+		gBMFont.renderText((SCREEN_WIDTH-w)/2, (SCREEN_HEIGHT-h)/2, SAMPLE_TEXT)
+
+		// Render the grid font at the top
+		gGridFont.renderText(8, 8, "Fixed grid font sheet, 8x16 cells")
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,473 @@
+package main
+
+import (
+	"flag"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+	"strconv"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 3
+
+// Size of the minimap in the top right corner
+const (
+	MINIMAP_WIDTH  = SCREEN_WIDTH / 4
+	MINIMAP_HEIGHT = SCREEN_HEIGHT / 4
+)
+
+// Size of the info panel in the bottom left corner
+const (
+	PANEL_WIDTH  = 240
+	PANEL_HEIGHT = 80
+)
+
+// Alpha change per frame while fading
+const FADE_STEP = 8
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gFont *ttf.Font
+var gDotTexture *MyTexture
+
+// Render targets
+var gSceneTexture *MyTexture
+var gMinimapTexture *MyTexture
+var gPanelTexture *MyTexture
+var gFrameTexture *MyTexture
+
+// Use the software renderer, e.g. to run headless with SDL_VIDEODRIVER=dummy
+var gSoftware = flag.Bool("software", false, "use the software renderer")
+
+// Quit after this many frames; 0 runs until the window is closed
+var gFrames = flag.Int("frames", 0, "number of frames to render before quitting")
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y       int32
+	velX, velY int32
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX -= DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX += DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+func (d *dot) move() {
+	// Move the dot left or right
+	d.x += d.velX
+
+	// If the dot went too far to the left or right
+	if d.x < 0 || d.x+DOT_WIDTH > SCREEN_WIDTH {
+		d.x -= d.velX
+	}
+
+	// Move the dot up or down
+	d.y += d.velY
+
+	// If the dot went too far to the up or down
+	if d.y < 0 || d.y+DOT_HEIGHT > SCREEN_HEIGHT {
+		d.y -= d.velY
+	}
+}
+
+func (d *dot) render() {
+	gDotTexture.render(d.x, d.y, nil)
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+// Creates a texture that can be drawn into with pushRenderTarget
+func NewTargetMyTexture(renderer *sdl.Renderer, width, height int32) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.createBlank(width, height, sdl.TEXTUREACCESS_TARGET)
+
+	// Keep transparent parts transparent when drawn onto something else
+	t.setBlendMode(sdl.BLENDMODE_BLEND)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) createBlank(width, height int32, access int) {
+	// Free pre-existing texture
+	t.free()
+
+	var err error
+	t.texture, err = t.renderer.CreateTexture(sdl.PIXELFORMAT_RGBA8888, access, int(width), int(height))
+	must(err)
+
+	t.width = width
+	t.height = height
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+// Render stretched to fill dst
+func (t *MyTexture) renderScaled(clip *sdl.Rect, dst *sdl.Rect) {
+	gRenderer.Copy(t.texture, clip, dst)
+}
+
+/* ------------------------------ render targets ------------------------------ */
+
+// Textures currently drawn into, innermost last. Every push must be
+// matched by a pop; the window is the target once the stack is empty.
+var gTargetStack []*MyTexture
+
+// Make the texture the target of all drawing until popRenderTarget
+func (t *MyTexture) pushRenderTarget() {
+	must(t.renderer.SetRenderTarget(t.texture))
+	gTargetStack = append(gTargetStack, t)
+}
+
+// Go back to drawing into the previous target
+func popRenderTarget() {
+	top := gTargetStack[len(gTargetStack)-1]
+	gTargetStack = gTargetStack[:len(gTargetStack)-1]
+
+	var previous *sdl.Texture
+	if len(gTargetStack) > 0 {
+		previous = gTargetStack[len(gTargetStack)-1].texture
+	}
+	must(top.renderer.SetRenderTarget(previous))
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer; both kinds need to support render targets
+	var flags uint32 = sdl.RENDERER_ACCELERATED | sdl.RENDERER_PRESENTVSYNC | sdl.RENDERER_TARGETTEXTURE
+	if *gSoftware {
+		flags = sdl.RENDERER_SOFTWARE | sdl.RENDERER_TARGETTEXTURE
+	}
+	renderer, err := sdl.CreateRenderer(window, -1, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init font system
+	err = ttf.Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+
+	var err error
+	gFont, err = ttf.OpenFont("assets/lazy.ttf", 20)
+	must(err)
+
+	gSceneTexture = NewTargetMyTexture(gRenderer, SCREEN_WIDTH, SCREEN_HEIGHT)
+	gMinimapTexture = NewTargetMyTexture(gRenderer, MINIMAP_WIDTH, MINIMAP_HEIGHT)
+	gPanelTexture = NewTargetMyTexture(gRenderer, PANEL_WIDTH, PANEL_HEIGHT)
+	gFrameTexture = NewTargetMyTexture(gRenderer, SCREEN_WIDTH, SCREEN_HEIGHT)
+}
+
+func close() {
+	gDotTexture.free()
+	gSceneTexture.free()
+	gMinimapTexture.free()
+	gPanelTexture.free()
+	gFrameTexture.free()
+
+	gFont.Close()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	ttf.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Draw the game world
+func renderScene(d *dot) {
+	gRenderer.SetDrawColor(255, 255, 255, 255)
+	gRenderer.Clear()
+
+	// Some geometry from lesson 8 to look at
+	gRenderer.SetDrawColor(255, 0, 0, 255)
+	gRenderer.FillRect(&sdl.Rect{SCREEN_WIDTH / 4, SCREEN_HEIGHT / 4, SCREEN_WIDTH / 2, SCREEN_HEIGHT / 2})
+	gRenderer.SetDrawColor(0, 255, 0, 255)
+	gRenderer.DrawRect(&sdl.Rect{SCREEN_WIDTH / 6, SCREEN_HEIGHT / 6, SCREEN_WIDTH * 2 / 3, SCREEN_HEIGHT * 2 / 3})
+	gRenderer.SetDrawColor(0, 0, 255, 255)
+	gRenderer.DrawLine(0, SCREEN_HEIGHT/2, SCREEN_WIDTH, SCREEN_HEIGHT/2)
+
+	d.render()
+}
+
+// Shrink the scene into the minimap
+func renderMinimap() {
+	gRenderer.SetDrawColor(0, 0, 0, 255)
+	gRenderer.Clear()
+	gSceneTexture.renderScaled(nil, &sdl.Rect{2, 2, MINIMAP_WIDTH - 4, MINIMAP_HEIGHT - 4})
+}
+
+// The panel is only redrawn when its text changes
+func renderPanel(text string) {
+	gRenderer.SetDrawColor(0, 0, 0, 160)
+	gRenderer.Clear()
+	gRenderer.SetDrawColor(255, 255, 255, 255)
+	gRenderer.DrawRect(&sdl.Rect{0, 0, PANEL_WIDTH, PANEL_HEIGHT})
+
+	textTexture := NewTextMyTexture(gRenderer, text, gFont, sdl.Color{255, 255, 255, 255})
+	textTexture.render((PANEL_WIDTH-textTexture.width)/2, (PANEL_HEIGHT-textTexture.height)/2, nil)
+	textTexture.free()
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	flag.Parse()
+
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	if !gRenderer.RenderTargetSupported() {
+		panic("render targets are not supported")
+	}
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var d dot
+
+	// Alpha of the whole frame and where it is fading to
+	var frameAlpha uint8 = 255
+	fadeIn := true
+
+	// What the panel currently shows
+	var panelText string
+	var fades int
+
+	var quit bool
+	for frame := 0; !quit; frame++ {
+		if *gFrames > 0 && frame >= *gFrames {
+			break
+		}
+
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				// Toggle fading of the whole frame
+				if t.Keysym.Scancode == sdl.SCANCODE_F && t.Repeat == 0 {
+					fadeIn = !fadeIn
+					fades++
+				}
+			}
+
+			d.handleEvent(event)
+		}
+
+		// Move the dot
+		d.move()
+
+		// Step the fade
+		switch {
+		case fadeIn && frameAlpha > 255-FADE_STEP:
+			frameAlpha = 255
+		case fadeIn:
+			frameAlpha += FADE_STEP
+		case frameAlpha < FADE_STEP:
+			frameAlpha = 0
+		default:
+			frameAlpha -= FADE_STEP
+		}
+
+		// Draw the world into its own texture
+		gSceneTexture.pushRenderTarget()
+		renderScene(&d)
+		popRenderTarget()
+
+		// Compose the frame from the scene, the minimap and the panel
+		gFrameTexture.pushRenderTarget()
+		gSceneTexture.render(0, 0, nil)
+
+		// Targets nest, drawing goes back to the frame after the pop
+		gMinimapTexture.pushRenderTarget()
+		renderMinimap()
+		popRenderTarget()
+		gMinimapTexture.render(SCREEN_WIDTH-MINIMAP_WIDTH-8, 8, nil)
+
+		text := "Fades: " + strconv.Itoa(fades)
+		if text != panelText {
+			gPanelTexture.pushRenderTarget()
+			renderPanel(text)
+			popRenderTarget()
+			panelText = text
+		}
+		gPanelTexture.render(8, SCREEN_HEIGHT-PANEL_HEIGHT-8, nil)
+		popRenderTarget()
+
+		// Clear screen
+		gRenderer.SetDrawColor(0, 0, 0, 255)
+		gRenderer.Clear()
+
+		// Render the composed frame with its fade
+		gFrameTexture.setAlpha(frameAlpha)
+		gFrameTexture.render(0, 0, nil)
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"testing"
+	"unsafe"
+)
+
+// Reads one pixel of the current render target
+func readPixel(t *testing.T, renderer *sdl.Renderer, x, y int32) sdl.Color {
+	t.Helper()
+
+	var pixel uint32
+	if err := renderer.ReadPixels(&sdl.Rect{x, y, 1, 1}, sdl.PIXELFORMAT_RGBA8888, unsafe.Pointer(&pixel), 4); err != nil {
+		t.Fatal(err)
+	}
+	return sdl.Color{uint8(pixel >> 24), uint8(pixel >> 16), uint8(pixel >> 8), uint8(pixel)}
+}
+
+func clearTo(renderer *sdl.Renderer, c sdl.Color) {
+	renderer.SetDrawColor(c.R, c.G, c.B, c.A)
+	renderer.Clear()
+}
+
+// Nests two targets on a software renderer drawing into a surface, so it
+// runs headless, and checks which target every clear and fill ends up in
+func TestRenderTargetStack(t *testing.T) {
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, 8, 8, 32, sdl.PIXELFORMAT_RGBA8888)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer surface.Free()
+
+	renderer, err := sdl.CreateSoftwareRenderer(surface)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer renderer.Destroy()
+
+	outer := NewTargetMyTexture(renderer, 4, 4)
+	defer outer.free()
+	inner := NewTargetMyTexture(renderer, 4, 4)
+	defer inner.free()
+
+	red := sdl.Color{255, 0, 0, 255}
+	green := sdl.Color{0, 255, 0, 255}
+	blue := sdl.Color{0, 0, 255, 255}
+	yellow := sdl.Color{255, 255, 0, 255}
+
+	clearTo(renderer, red)
+
+	outer.pushRenderTarget()
+	clearTo(renderer, green)
+
+	inner.pushRenderTarget()
+	clearTo(renderer, blue)
+	if c := readPixel(t, renderer, 0, 0); c != blue {
+		t.Errorf("inner target: got %v, want %v", c, blue)
+	}
+
+	// Back in the outer target, which the inner clear didn't touch
+	popRenderTarget()
+	if c := readPixel(t, renderer, 0, 0); c != green {
+		t.Errorf("outer target after pop: got %v, want %v", c, green)
+	}
+	renderer.SetDrawColor(yellow.R, yellow.G, yellow.B, yellow.A)
+	renderer.FillRect(&sdl.Rect{0, 0, 1, 1})
+
+	// Back in the surface
+	popRenderTarget()
+	if renderer.GetRenderTarget() != nil {
+		t.Error("the window is not the target once the stack is empty")
+	}
+	if c := readPixel(t, renderer, 0, 0); c != red {
+		t.Errorf("window after pops: got %v, want %v", c, red)
+	}
+	if len(gTargetStack) != 0 {
+		t.Errorf("%d targets left on the stack", len(gTargetStack))
+	}
+
+	// The fill after the first pop went into the outer target
+	outer.pushRenderTarget()
+	if c := readPixel(t, renderer, 0, 0); c != yellow {
+		t.Errorf("outer target filled after pop: got %v, want %v", c, yellow)
+	}
+	if c := readPixel(t, renderer, 1, 1); c != green {
+		t.Errorf("outer target next to the fill: got %v, want %v", c, green)
+	}
+	popRenderTarget()
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_mixer"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+	"os"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const TEXT_FONT_SIZE = 16
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+// Compiled into the binary, so it runs from any working directory
+//
+//go:embed assets
+var gEmbeddedAssets embed.FS
+
+var gAssets assetFS
+
+var gPromptTexture *MyTexture
+var gFont *ttf.Font
+
+// Music
+var gMusic *mix.Music
+
+// Sounds
+var gHigh *mix.Chunk
+var gMedium *mix.Chunk
+var gLow *mix.Chunk
+var gScratch *mix.Chunk
+
+var gAssetDir = flag.String("assets", "", "loose directory searched before the embedded assets, e.g. .")
+var gPackPath = flag.String("pak", "", "packed archive searched before the embedded assets")
+var gPackOut = flag.String("pack", "", "pack ./assets into this archive and exit")
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+	}
+}
+
+// Loads from the asset filesystem rather than the working directory
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := gAssets.loadSurface(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init font system
+	err = ttf.Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init sound system
+	err = mix.OpenAudio(44100, mix.DEFAULT_FORMAT, 2, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+// Searches the loose directory first, then the archive, then the embedded files
+func initAssets() error {
+	if *gAssetDir != "" {
+		gAssets.addLayer("directory "+*gAssetDir, os.DirFS(*gAssetDir))
+	}
+	if *gPackPath != "" {
+		archive, err := openPackArchive(*gPackPath)
+		if err != nil {
+			return err
+		}
+		gAssets.addLayer("archive "+*gPackPath, archive)
+	}
+	gAssets.addLayer("embedded", gEmbeddedAssets)
+	return nil
+}
+
+func loadMedia() {
+	gPromptTexture = NewMyTexture(gRenderer, "assets/prompt.png", nil)
+
+	var err error
+	gFont, err = gAssets.loadFont("assets/lazy.ttf", TEXT_FONT_SIZE)
+	must(err)
+
+	gMusic, err = gAssets.loadMusic("assets/beat.wav")
+	must(err)
+
+	gHigh, err = gAssets.loadWAV("assets/high.wav")
+	must(err)
+	gMedium, err = gAssets.loadWAV("assets/medium.wav")
+	must(err)
+	gLow, err = gAssets.loadWAV("assets/low.wav")
+	must(err)
+	gScratch, err = gAssets.loadWAV("assets/scratch.wav")
+	must(err)
+}
+
+func close() {
+	gPromptTexture.free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	gMusic.Free()
+	gHigh.Free()
+	gMedium.Free()
+	gLow.Free()
+	gScratch.Free()
+	gFont.Close()
+
+	// Only once nothing reads from the asset memory anymore
+	gAssets.close()
+
+	// Quit SDL subsystems
+	mix.Quit()
+	ttf.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Lists where each asset was read from
+func renderSources(names []string) {
+	y := SCREEN_HEIGHT - int32(len(names))*(TEXT_FONT_SIZE+4) - 10
+	for _, name := range names {
+		text := NewTextMyTexture(gRenderer, name+": "+gAssets.source(name), gFont, sdl.Color{0, 0, 0, 255})
+		text.render(10, y, nil)
+		text.free()
+		y += TEXT_FONT_SIZE + 4
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	flag.Parse()
+
+	if *gPackOut != "" {
+		if err := writePackArchive(*gPackOut, os.DirFS("."), "assets"); err != nil {
+			fmt.Fprintln(os.Stderr, "packing failed:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	must(initAssets())
+
+	var err error
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	sources := []string{"assets/prompt.png", "assets/lazy.ttf", "assets/beat.wav", "assets/high.wav"}
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_1:
+					gHigh.Play(-1, 0)
+				case sdl.SCANCODE_2:
+					gMedium.Play(-1, 0)
+				case sdl.SCANCODE_3:
+					gLow.Play(-1, 0)
+				case sdl.SCANCODE_4:
+					gScratch.Play(-1, 0)
+				case sdl.SCANCODE_9:
+					switch {
+					case !mix.PlayingMusic():
+						gMusic.Play(-1)
+					case mix.PausedMusic():
+						mix.ResumeMusic()
+					default:
+						mix.PauseMusic()
+					}
+				case sdl.SCANCODE_0:
+					mix.HaltMusic()
+				}
+			}
+		}
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// Render prompt texture
+		gPromptTexture.render(0, 0, nil)
+
+		renderSources(sources)
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
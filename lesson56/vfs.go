@@ -0,0 +1,357 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_mixer"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"time"
+	"unsafe"
+)
+
+/* ------------------------------ asset filesystem ------------------------------ */
+
+// A named source of assets, such as the embedded files, a loose directory
+// from os.DirFS or a packed archive
+type assetLayer struct {
+	name string
+	fsys fs.FS
+}
+
+// Reads assets by slash-separated name, like "assets/prompt.png", from the
+// first layer that has the file. Put loose directories first so they can
+// override packed or embedded files.
+type assetFS struct {
+	layers []assetLayer
+
+	// Fonts and music are read by SDL long after they are opened, so their
+	// memory stays pinned until the asset filesystem is closed
+	pinner runtime.Pinner
+}
+
+func (a *assetFS) addLayer(name string, fsys fs.FS) {
+	a.layers = append(a.layers, assetLayer{name, fsys})
+}
+
+// Returns the contents of a file and the name of the layer it came from
+func (a *assetFS) readFile(name string) ([]byte, string, error) {
+	for _, layer := range a.layers {
+		data, err := fs.ReadFile(layer.fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, layer.name, err
+		}
+		return data, layer.name, nil
+	}
+	return nil, "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Name of the layer a file would be read from, or "" if no layer has it
+func (a *assetFS) source(name string) string {
+	for _, layer := range a.layers {
+		if _, err := fs.Stat(layer.fsys, name); err == nil {
+			return layer.name
+		}
+	}
+	return ""
+}
+
+// Opens a file as a memory RWops. SDL holds on to the data beyond this call,
+// so it is pinned; the returned release function unpins it again and must be
+// called once SDL no longer reads from the RWops.
+func (a *assetFS) open(name string) (*sdl.RWops, func(), error) {
+	data, _, err := a.readFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("%s: empty file", name)
+	}
+
+	var pinner runtime.Pinner
+	pinner.Pin(&data[0])
+
+	rw := sdl.RWFromMem(unsafe.Pointer(&data[0]), len(data))
+	if rw == nil {
+		pinner.Unpin()
+		return nil, nil, sdl.GetError()
+	}
+	return rw, pinner.Unpin, nil
+}
+
+// Like open, but the data stays pinned until close, for loaders that keep
+// reading from the RWops
+func (a *assetFS) openPersistent(name string) (*sdl.RWops, error) {
+	data, _, err := a.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%s: empty file", name)
+	}
+
+	a.pinner.Pin(&data[0])
+
+	rw := sdl.RWFromMem(unsafe.Pointer(&data[0]), len(data))
+	if rw == nil {
+		return nil, sdl.GetError()
+	}
+	return rw, nil
+}
+
+func (a *assetFS) loadSurface(name string) (*sdl.Surface, error) {
+	rw, release, err := a.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Let SDL_image free the RWops
+	return img.Load_RW(rw, 1)
+}
+
+func (a *assetFS) loadFont(name string, size int) (*ttf.Font, error) {
+	// Glyphs are read from the RWops as they are needed
+	rw, err := a.openPersistent(name)
+	if err != nil {
+		return nil, err
+	}
+	return ttf.OpenFontRW(rw, 1, size)
+}
+
+func (a *assetFS) loadWAV(name string) (*mix.Chunk, error) {
+	rw, release, err := a.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return mix.LoadWAV_RW(rw, true)
+}
+
+func (a *assetFS) loadMusic(name string) (*mix.Music, error) {
+	// Music is streamed from the RWops while it plays
+	rw, err := a.openPersistent(name)
+	if err != nil {
+		return nil, err
+	}
+	return mix.LoadMUS_RW(rw, 1)
+}
+
+// Call after freeing every font and music loaded from the asset filesystem
+func (a *assetFS) close() {
+	a.pinner.Unpin()
+	for _, layer := range a.layers {
+		if c, ok := layer.fsys.(io.Closer); ok {
+			c.Close()
+		}
+	}
+	a.layers = nil
+}
+
+/* ------------------------------ packed archive ------------------------------ */
+
+// Archive layout, all integers little endian:
+//
+//	magic        "SDLPAK01"
+//	count        uint32
+//	count times:
+//	  nameLength uint16
+//	  name       nameLength bytes, slash-separated
+//	  offset     uint64, from the start of the archive
+//	  size       uint64
+//	file data
+const PACK_MAGIC = "SDLPAK01"
+
+type packEntry struct {
+	offset int64
+	size   int64
+}
+
+// A read-only fs.FS over a packed archive. Files are read straight from the
+// archive on disk; only the index is kept in memory. Directories can not be
+// opened, so fs.WalkDir does not work on it.
+type packArchive struct {
+	file  *os.File
+	index map[string]packEntry
+}
+
+func openPackArchive(path string) (*packArchive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	index, err := readPackIndex(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &packArchive{file, index}, nil
+}
+
+// Smallest index entry: an empty name, the offset and the size
+const PACK_ENTRY_MIN_SIZE = 2 + 8 + 8
+
+// Reads the index of an archive of size bytes. A corrupt archive gives an
+// error, never an entry outside the archive or a huge allocation.
+func readPackIndex(r io.Reader, size int64) (map[string]packEntry, error) {
+	magic := make([]byte, len(PACK_MAGIC))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != PACK_MAGIC {
+		return nil, errors.New("not a packed asset archive")
+	}
+
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+
+	// Every entry takes room, which bounds the count by the file size
+	if int64(count) > (size-int64(len(PACK_MAGIC))-4)/PACK_ENTRY_MIN_SIZE {
+		return nil, fmt.Errorf("%d entries don't fit in %d bytes", count, size)
+	}
+
+	index := make(map[string]packEntry, count)
+	for i := uint32(0); i < count; i++ {
+		var nameLength uint16
+		if err := binary.Read(r, binary.LittleEndian, &nameLength); err != nil {
+			return nil, err
+		}
+		name := make([]byte, nameLength)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, err
+		}
+		var entry [2]uint64
+		if err := binary.Read(r, binary.LittleEndian, &entry); err != nil {
+			return nil, err
+		}
+		offset, length := entry[0], entry[1]
+		if offset > uint64(size) || length > uint64(size)-offset {
+			return nil, fmt.Errorf("%s: %d bytes at %d are outside of the archive", name, length, offset)
+		}
+		index[string(name)] = packEntry{int64(offset), int64(length)}
+	}
+	return index, nil
+}
+
+func (p *packArchive) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := p.index[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &packFile{io.NewSectionReader(p.file, entry.offset, entry.size), name}, nil
+}
+
+func (p *packArchive) Close() error {
+	return p.file.Close()
+}
+
+type packFile struct {
+	*io.SectionReader
+	name string
+}
+
+func (f *packFile) Stat() (fs.FileInfo, error) {
+	return packFileInfo{f}, nil
+}
+
+func (f *packFile) Close() error {
+	return nil
+}
+
+type packFileInfo struct {
+	f *packFile
+}
+
+func (i packFileInfo) Name() string       { return path.Base(i.f.name) }
+func (i packFileInfo) Size() int64        { return i.f.Size() }
+func (i packFileInfo) Mode() fs.FileMode  { return 0444 }
+func (i packFileInfo) ModTime() time.Time { return time.Time{} }
+func (i packFileInfo) IsDir() bool        { return false }
+func (i packFileInfo) Sys() interface{}   { return nil }
+
+// Packs every file below root in fsys into a new archive at path. Files keep
+// their full names, so "assets/prompt.png" is found under the same name as
+// in the embedded files or a loose directory.
+func writePackArchive(path string, fsys fs.FS, root string) error {
+	var names []string
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	var sizes []int64
+	headerSize := int64(len(PACK_MAGIC) + 4)
+	for _, name := range names {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return err
+		}
+		sizes = append(sizes, info.Size())
+		headerSize += 2 + int64(len(name)) + 8 + 8
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Write the index, then the files in the same order
+	header := []byte(PACK_MAGIC)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(names)))
+	offset := headerSize
+	for i, name := range names {
+		header = binary.LittleEndian.AppendUint16(header, uint16(len(name)))
+		header = append(header, name...)
+		header = binary.LittleEndian.AppendUint64(header, uint64(offset))
+		header = binary.LittleEndian.AppendUint64(header, uint64(sizes[i]))
+		offset += sizes[i]
+	}
+	if _, err := file.Write(header); err != nil {
+		return err
+	}
+
+	for i, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) != sizes[i] {
+			return fmt.Errorf("%s changed while packing", name)
+		}
+		if _, err := file.Write(data); err != nil {
+			return err
+		}
+	}
+	return file.Close()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Every file of a directory comes back byte for byte from its archive
+func TestPackArchiveRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"assets/prompt.png":        "not really a png",
+		"assets/empty.wav":         "",
+		"assets/fonts/lazy.ttf":    "font data",
+		"assets/music/beat 01.wav": string(bytes.Repeat([]byte{0, 1, 2, 255}, 1000)),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "assets.pak")
+	if err := writePackArchive(archivePath, os.DirFS(dir), "assets"); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := openPackArchive(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	if len(archive.index) != len(files) {
+		t.Errorf("%d files in the archive, want %d", len(archive.index), len(files))
+	}
+	for name, want := range files {
+		f, err := archive.Open(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if string(data) != want {
+			t.Errorf("%s: got %d bytes, want %d", name, len(data), len(want))
+		}
+	}
+
+	if _, err := archive.Open("assets/missing.png"); err == nil {
+		t.Error("opened a file that is not in the archive")
+	}
+}
+
+// Builds an archive with one entry named a, followed by dataSize bytes
+func packHeader(count uint32, offset, size uint64, dataSize int) []byte {
+	var b bytes.Buffer
+	b.WriteString(PACK_MAGIC)
+	binary.Write(&b, binary.LittleEndian, count)
+	binary.Write(&b, binary.LittleEndian, uint16(1))
+	b.WriteString("a")
+	binary.Write(&b, binary.LittleEndian, [2]uint64{offset, size})
+	b.Write(make([]byte, dataSize))
+	return b.Bytes()
+}
+
+func TestReadPackIndexRejects(t *testing.T) {
+	const dataStart = uint64(len(PACK_MAGIC)) + 4 + 2 + 1 + 8 + 8
+
+	valid := packHeader(1, dataStart, 4, 4)
+	if index, err := readPackIndex(bytes.NewReader(valid), int64(len(valid))); err != nil {
+		t.Fatal(err)
+	} else if index["a"] != (packEntry{int64(dataStart), 4}) {
+		t.Fatalf("got %+v", index["a"])
+	}
+
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"not an archive", []byte("PK\x03\x04 a zip file")},
+		{"huge count", packHeader(0xffffffff, dataStart, 4, 4)},
+		{"more entries than written", packHeader(2, dataStart, 4, 4)},
+		{"size past the end", packHeader(1, dataStart, 5, 4)},
+		{"offset past the end", packHeader(1, dataStart+5, 0, 4)},
+		{"negative offset", packHeader(1, 1<<63, 4, 4)},
+		{"negative size", packHeader(1, dataStart, 1<<63, 4)},
+		{"offset and size overflow", packHeader(1, dataStart, 1<<64-1, 4)},
+	} {
+		if _, err := readPackIndex(bytes.NewReader(c.data), int64(len(c.data))); err == nil {
+			t.Errorf("%s: read", c.name)
+		}
+	}
+}
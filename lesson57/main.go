@@ -0,0 +1,364 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_mixer"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+	"os"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	BUTTON_WIDTH  = 300
+	BUTTON_HEIGHT = 200
+)
+
+const BUTTON_NUM = 4
+const BUTTON_SPRITE_TOTAL = 4
+
+const (
+	BUTTON_SPRITE_MOUSE_OUT = iota
+	BUTTON_SPRITE_MOUSE_OVER_MOTION
+	BUTTON_SPRITE_MOUSE_DOWN
+	BUTTON_SPRITE_MOUSE_UP
+)
+
+const TEXT_FONT_SIZE = 16
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gResources *resourceManager
+
+var gButtons [BUTTON_NUM]*button
+var gFont *resourceHandle
+
+// Handles deliberately never released, to show up in the shutdown report
+var gLeaked []*resourceHandle
+
+// Sound each button plays when clicked
+var gButtonSounds = [BUTTON_NUM]string{
+	"assets/high.wav",
+	"assets/medium.wav",
+	"assets/low.wav",
+	"assets/scratch.wav",
+}
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type buttonSpriteIndex int
+
+type button struct {
+	// Top left position
+	position sdl.Point
+
+	// Every button holds its own handle to the shared sprite sheet
+	spriteSheet *resourceHandle
+	sound       *resourceHandle
+
+	spriteIndex buttonSpriteIndex
+
+	spriteClips [BUTTON_SPRITE_TOTAL]sdl.Rect
+}
+
+func NewButton(position sdl.Point, spriteSheet, sound *resourceHandle) *button {
+	b := &button{
+		position:    position,
+		spriteSheet: spriteSheet,
+		sound:       sound,
+	}
+	for i := 0; i < BUTTON_SPRITE_TOTAL; i++ {
+		b.spriteClips[i] = sdl.Rect{0, int32(i * 200), BUTTON_WIDTH, BUTTON_HEIGHT}
+	}
+	return b
+}
+
+func (b *button) free() {
+	b.spriteSheet.release()
+	b.sound.release()
+}
+
+func (b *button) setPosition(x, y int32) {
+	b.position.X = x
+	b.position.Y = y
+}
+
+func (b *button) render() {
+	b.spriteSheet.texture().render(b.position.X, b.position.Y, &b.spriteClips[b.spriteIndex])
+}
+
+func (b *button) handleEvent(e sdl.Event) {
+	var mouseEventType uint32
+	switch t := e.(type) {
+	case *sdl.MouseMotionEvent:
+		mouseEventType = t.Type
+	case *sdl.MouseButtonEvent:
+		mouseEventType = t.Type
+	default:
+		return
+	}
+
+	var inside bool = true
+	x, y, _ := sdl.GetMouseState()
+	if int32(x) < b.position.X || int32(x) > b.position.X+BUTTON_WIDTH {
+		inside = false
+	}
+	if int32(y) < b.position.Y || int32(y) > b.position.Y+BUTTON_HEIGHT {
+		inside = false
+	}
+
+	if !inside {
+		b.spriteIndex = BUTTON_SPRITE_MOUSE_OUT
+	} else {
+		if mouseEventType == sdl.MOUSEMOTION {
+			b.spriteIndex = BUTTON_SPRITE_MOUSE_OVER_MOTION
+		}
+		if mouseEventType == sdl.MOUSEBUTTONDOWN {
+			b.spriteIndex = BUTTON_SPRITE_MOUSE_DOWN
+			b.sound.chunk().Play(-1, 0)
+		}
+		if mouseEventType == sdl.MOUSEBUTTONUP {
+			b.spriteIndex = BUTTON_SPRITE_MOUSE_UP
+		}
+	}
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init font system
+	err = ttf.Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init sound system
+	err = mix.OpenAudio(44100, mix.DEFAULT_FORMAT, 2, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gResources = NewResourceManager(gRenderer)
+
+	// The sprite sheet is loaded once and shared by all four buttons
+	for i := 0; i < BUTTON_NUM; i++ {
+		gButtons[i] = NewButton(sdl.Point{},
+			gResources.loadTexture("assets/button.png", nil),
+			gResources.loadSound(gButtonSounds[i]))
+	}
+	gButtons[0].setPosition(0, 0)
+	gButtons[1].setPosition(SCREEN_WIDTH-BUTTON_WIDTH, 0)
+	gButtons[2].setPosition(0, SCREEN_HEIGHT-BUTTON_HEIGHT)
+	gButtons[3].setPosition(SCREEN_WIDTH-BUTTON_WIDTH, SCREEN_HEIGHT-BUTTON_HEIGHT)
+
+	gFont = gResources.loadFont("assets/lazy.ttf", TEXT_FONT_SIZE)
+}
+
+func close() {
+	for i := 0; i < BUTTON_NUM; i++ {
+		gButtons[i].free()
+	}
+	gFont.release()
+
+	// Frees anything left over, so it must run before the renderer goes
+	for _, line := range gResources.shutdown() {
+		fmt.Fprintln(os.Stderr, "resources:", line)
+	}
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	mix.Quit()
+	ttf.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Renders the cache statistics and key help between the buttons
+func renderStats() {
+	lines := []string{
+		gResources.stats(),
+		"L: leak a handle  D: release a handle twice",
+	}
+	y := int32(SCREEN_HEIGHT/2 - TEXT_FONT_SIZE - 4)
+	for _, line := range lines {
+		text := NewTextMyTexture(gRenderer, line, gFont.font(), sdl.Color{0, 0, 0, 255})
+		text.render((SCREEN_WIDTH-text.width)/2, y, nil)
+		text.free()
+		y += TEXT_FONT_SIZE + 4
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_L:
+					// Served from the cache, but never released
+					gLeaked = append(gLeaked, gResources.loadTexture("assets/button.png", nil))
+				case sdl.SCANCODE_D:
+					// The second release is caught, the buttons keep their texture
+					h := gResources.loadTexture("assets/button.png", nil)
+					h.release()
+					h.release()
+				}
+			}
+
+			for i := 0; i < BUTTON_NUM; i++ {
+				gButtons[i].handleEvent(event)
+			}
+		}
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// Render buttons
+		for i := 0; i < BUTTON_NUM; i++ {
+			gButtons[i].render()
+		}
+
+		renderStats()
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_mixer"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+	"sort"
+)
+
+/* ------------------------------ resource manager ------------------------------ */
+
+const (
+	RESOURCE_TEXTURE = "texture"
+	RESOURCE_FONT    = "font"
+	RESOURCE_SOUND   = "sound"
+)
+
+// One loaded file, shared by every handle to it
+type resource struct {
+	kind string
+	key  string
+
+	// Live handles; the resource is freed when the last one is released
+	refs int
+
+	// Exactly one of these is set, depending on kind
+	texture *MyTexture
+	font    *ttf.Font
+	chunk   *mix.Chunk
+}
+
+func (r *resource) free() {
+	switch r.kind {
+	case RESOURCE_TEXTURE:
+		r.texture.free()
+	case RESOURCE_FONT:
+		r.font.Close()
+	case RESOURCE_SOUND:
+		r.chunk.Free()
+	}
+}
+
+// A reference to a resource. Every handle must be released exactly once;
+// the manager reports handles that never are and handles released twice.
+type resourceHandle struct {
+	manager  *resourceManager
+	res      *resource
+	released bool
+}
+
+func (h *resourceHandle) texture() *MyTexture {
+	h.check()
+	return h.res.texture
+}
+
+func (h *resourceHandle) font() *ttf.Font {
+	h.check()
+	return h.res.font
+}
+
+func (h *resourceHandle) chunk() *mix.Chunk {
+	h.check()
+	return h.res.chunk
+}
+
+// Using a released handle would touch memory that may already be freed
+func (h *resourceHandle) check() {
+	if h.released {
+		panic(fmt.Sprintf("use of released %s %s", h.res.kind, h.res.key))
+	}
+}
+
+func (h *resourceHandle) release() {
+	h.manager.release(h)
+}
+
+// Loads textures, fonts and sounds once per file and shares them between
+// handles. Replaces freeing every asset by hand in close().
+type resourceManager struct {
+	renderer *sdl.Renderer
+
+	resources map[string]*resource
+
+	// Number of loads served from the cache, for statistics
+	hits int
+
+	// Problems found while running, listed by the shutdown report
+	doubleReleases []string
+}
+
+func NewResourceManager(renderer *sdl.Renderer) *resourceManager {
+	return &resourceManager{
+		renderer:  renderer,
+		resources: map[string]*resource{},
+	}
+}
+
+// Returns a new handle to the cached resource, or nil if it isn't loaded yet
+func (m *resourceManager) acquire(key string) *resourceHandle {
+	r, ok := m.resources[key]
+	if !ok {
+		return nil
+	}
+	r.refs++
+	m.hits++
+	return &resourceHandle{manager: m, res: r}
+}
+
+func (m *resourceManager) add(r *resource) *resourceHandle {
+	r.refs = 1
+	m.resources[r.key] = r
+	return &resourceHandle{manager: m, res: r}
+}
+
+// The color key is part of the cache key, as it changes the texture
+func (m *resourceManager) loadTexture(path string, colorKey *sdl.Color) *resourceHandle {
+	key := RESOURCE_TEXTURE + ":" + path
+	if colorKey != nil {
+		key += fmt.Sprintf("#%02x%02x%02x", colorKey.R, colorKey.G, colorKey.B)
+	}
+	if h := m.acquire(key); h != nil {
+		return h
+	}
+	return m.add(&resource{kind: RESOURCE_TEXTURE, key: key, texture: NewMyTexture(m.renderer, path, colorKey)})
+}
+
+func (m *resourceManager) loadFont(path string, size int) *resourceHandle {
+	key := fmt.Sprintf("%s:%s@%d", RESOURCE_FONT, path, size)
+	if h := m.acquire(key); h != nil {
+		return h
+	}
+	font, err := ttf.OpenFont(path, size)
+	must(err)
+	return m.add(&resource{kind: RESOURCE_FONT, key: key, font: font})
+}
+
+func (m *resourceManager) loadSound(path string) *resourceHandle {
+	key := RESOURCE_SOUND + ":" + path
+	if h := m.acquire(key); h != nil {
+		return h
+	}
+	chunk, err := mix.LoadWAV(path)
+	must(err)
+	return m.add(&resource{kind: RESOURCE_SOUND, key: key, chunk: chunk})
+}
+
+// A second release of the same handle is recorded and otherwise ignored, so
+// it can't free a resource other handles still use
+func (m *resourceManager) release(h *resourceHandle) {
+	if h.released {
+		m.doubleReleases = append(m.doubleReleases, h.res.key)
+		return
+	}
+	h.released = true
+
+	h.res.refs--
+	if h.res.refs == 0 {
+		h.res.free()
+		delete(m.resources, h.res.key)
+	}
+}
+
+// Resources and live handles per kind
+func (m *resourceManager) stats() string {
+	counts := map[string][2]int{}
+	for _, r := range m.resources {
+		c := counts[r.kind]
+		counts[r.kind] = [2]int{c[0] + 1, c[1] + r.refs}
+	}
+	s := ""
+	for _, kind := range []string{RESOURCE_TEXTURE, RESOURCE_FONT, RESOURCE_SOUND} {
+		s += fmt.Sprintf("%ss %d (%d refs)  ", kind, counts[kind][0], counts[kind][1])
+	}
+	return s + fmt.Sprintf("cache hits %d", m.hits)
+}
+
+// Frees whatever is still loaded and lists leaked resources and double
+// releases. An empty report means every handle was released exactly once.
+func (m *resourceManager) shutdown() []string {
+	var report []string
+
+	var keys []string
+	for key := range m.resources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		r := m.resources[key]
+		report = append(report, fmt.Sprintf("leaked %s, %d handle(s) never released", key, r.refs))
+		r.free()
+	}
+	m.resources = map[string]*resource{}
+
+	for _, key := range m.doubleReleases {
+		report = append(report, fmt.Sprintf("released %s twice", key))
+	}
+	m.doubleReleases = nil
+
+	return report
+}
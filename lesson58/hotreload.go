@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+/* ------------------------------ asset watcher ------------------------------ */
+
+// A file being watched, and how to load it again
+type watchedFile struct {
+	path string
+
+	// As seen when the file was last loaded or tried
+	modTime time.Time
+	size    int64
+
+	reload func() error
+}
+
+// Polls the modification times of loaded assets and reloads the ones that
+// changed. Polling needs no OS-specific watcher and is cheap for the few
+// files a lesson has.
+type assetWatcher struct {
+	files []*watchedFile
+
+	// Latest reload error per path, until the file loads again
+	errors map[string]error
+}
+
+func NewAssetWatcher() *assetWatcher {
+	return &assetWatcher{errors: map[string]error{}}
+}
+
+// Remembers the current state of the file; reload is called when it changes
+func (w *assetWatcher) watch(path string, reload func() error) {
+	f := &watchedFile{path: path, reload: reload}
+	if info, err := os.Stat(path); err == nil {
+		f.modTime = info.ModTime()
+		f.size = info.Size()
+	}
+	w.files = append(w.files, f)
+}
+
+// Reloads changed files and returns their paths. A file that fails to load
+// keeps its previous contents and isn't tried again until it changes again,
+// as editors often save in several steps.
+func (w *assetWatcher) poll() []string {
+	var reloaded []string
+	for _, f := range w.files {
+		info, err := os.Stat(f.path)
+		if err != nil {
+			// Deleted or being replaced; keep what is loaded
+			continue
+		}
+		if info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+			continue
+		}
+		f.modTime = info.ModTime()
+		f.size = info.Size()
+
+		if err := f.reload(); err != nil {
+			w.errors[f.path] = err
+			continue
+		}
+		delete(w.errors, f.path)
+		reloaded = append(reloaded, f.path)
+	}
+	return reloaded
+}
+
+// Current errors, one line each, in a stable order
+func (w *assetWatcher) errorLines() []string {
+	var lines []string
+	for path, err := range w.errors {
+		lines = append(lines, fmt.Sprintf("%s: %v", path, err))
+	}
+	sort.Strings(lines)
+	return lines
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"flag"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_mixer"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const TEXT_FONT_SIZE = 16
+
+// How often the watched assets are checked for changes
+const POLL_INTERVAL_MS = 500
+
+// How long the last reload stays on screen
+const RELOAD_MESSAGE_MS = 2000
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gImageTexture *MyTexture
+var gFont *myFont
+var gBeat *mySound
+var gHigh *mySound
+
+// Only set in dev mode
+var gWatcher *assetWatcher
+
+var gDevFlag = flag.Bool("dev", false, "reload assets when their files change")
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+// A font that can be reloaded; the *myFont stays valid when it is
+type myFont struct {
+	font *ttf.Font
+	size int
+}
+
+func NewMyFont(path string, size int) *myFont {
+	f := &myFont{size: size}
+	must(f.reloadFromFile(path))
+	return f
+}
+
+// Opens the new font first and only replaces the old one if that worked
+func (f *myFont) reloadFromFile(path string) error {
+	font, err := ttf.OpenFont(path, f.size)
+	if err != nil {
+		return err
+	}
+	f.free()
+	f.font = font
+	return nil
+}
+
+func (f *myFont) free() {
+	if f.font != nil {
+		f.font.Close()
+		f.font = nil
+	}
+}
+
+// A sound effect that can be reloaded; the *mySound stays valid when it is
+type mySound struct {
+	chunk *mix.Chunk
+}
+
+func NewMySound(path string) *mySound {
+	s := &mySound{}
+	must(s.reloadFromFile(path))
+	return s
+}
+
+// Loads the new chunk first and only replaces the old one if that worked.
+// Freeing the old chunk stops the channels still playing it.
+func (s *mySound) reloadFromFile(path string) error {
+	chunk, err := mix.LoadWAV(path)
+	if err != nil {
+		return err
+	}
+	s.free()
+	s.chunk = chunk
+	return nil
+}
+
+func (s *mySound) play() {
+	s.chunk.Play(-1, 0)
+}
+
+func (s *mySound) free() {
+	if s.chunk != nil {
+		s.chunk.Free()
+		s.chunk = nil
+	}
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	must(t.reloadFromFile(path, colorKey))
+}
+
+// Like loadFromFile, but keeps the current texture if the file can't be
+// loaded, so a half-saved image doesn't leave a hole on screen
+func (t *MyTexture) reloadFromFile(path string, colorKey *sdl.Color) error {
+	surface, err := img.Load(path)
+	if err != nil {
+		return err
+	}
+	defer surface.Free()
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	texture, err := t.renderer.CreateTextureFromSurface(surface)
+	if err != nil {
+		return err
+	}
+
+	// Swap in the new texture; the *MyTexture held by others stays valid
+	t.free()
+	t.texture = texture
+	t.width = surface.W
+	t.height = surface.H
+	return nil
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init font system
+	err = ttf.Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init sound system
+	err = mix.OpenAudio(44100, mix.DEFAULT_FORMAT, 2, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gImageTexture = NewMyTexture(gRenderer, "assets/fadein.png", nil)
+	gFont = NewMyFont("assets/lazy.ttf", TEXT_FONT_SIZE)
+	gBeat = NewMySound("assets/beat.wav")
+	gHigh = NewMySound("assets/high.wav")
+
+	if *gDevFlag {
+		gWatcher = NewAssetWatcher()
+		gWatcher.watch("assets/fadein.png", func() error {
+			return gImageTexture.reloadFromFile("assets/fadein.png", nil)
+		})
+		gWatcher.watch("assets/lazy.ttf", func() error {
+			return gFont.reloadFromFile("assets/lazy.ttf")
+		})
+		gWatcher.watch("assets/beat.wav", func() error {
+			return gBeat.reloadFromFile("assets/beat.wav")
+		})
+		gWatcher.watch("assets/high.wav", func() error {
+			return gHigh.reloadFromFile("assets/high.wav")
+		})
+	}
+}
+
+func close() {
+	gImageTexture.free()
+	gFont.free()
+	gBeat.free()
+	gHigh.free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	mix.Quit()
+	ttf.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func renderLines(lines []string, x, y int32, color sdl.Color) {
+	for _, line := range lines {
+		text := NewTextMyTexture(gRenderer, line, gFont.font, color)
+		text.render(x, y, nil)
+		text.free()
+		y += TEXT_FONT_SIZE + 4
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	flag.Parse()
+
+	var err error
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var lastPoll uint32
+	var lastReloaded []string
+	var lastReloadTime uint32
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_1:
+					gBeat.play()
+				case sdl.SCANCODE_2:
+					gHigh.play()
+				}
+			}
+		}
+
+		if gWatcher != nil && sdl.GetTicks()-lastPoll >= POLL_INTERVAL_MS {
+			lastPoll = sdl.GetTicks()
+			if reloaded := gWatcher.poll(); len(reloaded) > 0 {
+				lastReloaded = reloaded
+				lastReloadTime = lastPoll
+			}
+		}
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// Render the image
+		gImageTexture.render(0, 0, nil)
+
+		help := []string{"1: beat  2: high"}
+		if gWatcher != nil {
+			help = append(help, "Dev mode: edit the files in assets/ to reload them")
+		} else {
+			help = append(help, "Run with -dev to reload assets when they change")
+		}
+		renderLines(help, 10, 10, sdl.Color{0, 0, 0, 255})
+
+		if gWatcher != nil {
+			// Errors stay until the file loads again
+			errs := gWatcher.errorLines()
+			y := int32(SCREEN_HEIGHT - 10 - len(errs)*(TEXT_FONT_SIZE+4))
+			renderLines(errs, 10, y, sdl.Color{200, 0, 0, 255})
+
+			if len(lastReloaded) > 0 && sdl.GetTicks()-lastReloadTime < RELOAD_MESSAGE_MS {
+				var lines []string
+				for _, path := range lastReloaded {
+					lines = append(lines, "Reloaded "+path)
+				}
+				renderLines(lines, 10, 60, sdl.Color{0, 128, 0, 255})
+			}
+		}
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
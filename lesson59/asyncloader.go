@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_mixer"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/* ------------------------------ main-thread queue ------------------------------ */
+
+// What main-thread tasks may use. Only drain creates one, so code that
+// doesn't run on the main thread has no way to reach the renderer.
+type mainContext struct {
+	renderer *sdl.Renderer
+}
+
+// Runs functions posted from any goroutine on the main thread. SDL requires
+// textures to be created on the thread that owns the renderer.
+type mainQueue struct {
+	tasks chan func(ctx *mainContext)
+}
+
+func NewMainQueue(size int) *mainQueue {
+	return &mainQueue{make(chan func(ctx *mainContext), size)}
+}
+
+// Safe to call from any goroutine
+func (q *mainQueue) post(task func(ctx *mainContext)) {
+	q.tasks <- task
+}
+
+// Runs queued tasks until the queue is empty or the time budget is used up,
+// so uploading many textures doesn't stall a frame. Main thread only.
+func (q *mainQueue) drain(renderer *sdl.Renderer, budget time.Duration) {
+	ctx := &mainContext{renderer}
+	start := time.Now()
+	for time.Since(start) < budget {
+		select {
+		case task := <-q.tasks:
+			task(ctx)
+		default:
+			return
+		}
+	}
+}
+
+/* ------------------------------ async loader ------------------------------ */
+
+// Runs on a worker goroutine and decodes one asset. upload finishes it on the
+// main thread; discard frees what was decoded if loading was cancelled meanwhile.
+type decodeFunc func() (upload func(ctx *mainContext) error, discard func(), err error)
+
+type assetJob struct {
+	path   string
+	decode decodeFunc
+}
+
+// Decodes images and sounds on worker goroutines and hands them to the main
+// queue, which creates the textures. The loader itself holds no renderer;
+// results, errors and progress are only touched by main-thread tasks.
+type asyncLoader struct {
+	queue   *mainQueue
+	jobs    []assetJob
+	workers int
+
+	// Set from the main thread, read by the workers
+	cancelled atomic.Bool
+
+	// Main thread only
+	done     int
+	textures map[string]*MyTexture
+	sounds   map[string]*mix.Chunk
+	errors   []error
+
+	// Running workers, waited for by free
+	wg sync.WaitGroup
+}
+
+func NewAsyncLoader(workers int) *asyncLoader {
+	return &asyncLoader{
+		workers:  workers,
+		textures: map[string]*MyTexture{},
+		sounds:   map[string]*mix.Chunk{},
+	}
+}
+
+func (l *asyncLoader) addJob(path string, decode decodeFunc) {
+	l.jobs = append(l.jobs, assetJob{path, decode})
+}
+
+func (l *asyncLoader) addTexture(path string, colorKey *sdl.Color) {
+	l.addJob(path, func() (func(ctx *mainContext) error, func(), error) {
+		surface, err := img.Load(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if colorKey != nil {
+			surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+		}
+
+		upload := func(ctx *mainContext) error {
+			defer surface.Free()
+			t, err := NewMyTextureFromSurface(ctx.renderer, surface)
+			if err != nil {
+				return err
+			}
+			l.textures[path] = t
+			return nil
+		}
+		return upload, surface.Free, nil
+	})
+}
+
+func (l *asyncLoader) addSound(path string) {
+	l.addJob(path, func() (func(ctx *mainContext) error, func(), error) {
+		chunk, err := mix.LoadWAV(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		upload := func(ctx *mainContext) error {
+			l.sounds[path] = chunk
+			return nil
+		}
+		return upload, chunk.Free, nil
+	})
+}
+
+// Starts decoding. decodeDelay slows every decode down, to make the loading
+// screen visible with small assets.
+func (l *asyncLoader) start(decodeDelay time.Duration) {
+	// Every job posts exactly one task, so workers never block on the queue
+	l.queue = NewMainQueue(len(l.jobs))
+
+	// Workers take the next job by index until none are left
+	var next atomic.Int32
+	for i := 0; i < l.workers; i++ {
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(l.jobs) {
+					return
+				}
+				if !l.cancelled.Load() {
+					time.Sleep(decodeDelay)
+				}
+				l.decode(l.jobs[i])
+			}
+		}()
+	}
+}
+
+// Runs on a worker goroutine
+func (l *asyncLoader) decode(job assetJob) {
+	// Skipped jobs still count, so progress reaches the total
+	if l.cancelled.Load() {
+		l.queue.post(func(ctx *mainContext) {
+			l.done++
+		})
+		return
+	}
+
+	upload, discard, err := job.decode()
+	l.queue.post(func(ctx *mainContext) {
+		l.done++
+		if err == nil {
+			if l.cancelled.Load() {
+				discard()
+				return
+			}
+			err = upload(ctx)
+		}
+		if err != nil {
+			l.errors = append(l.errors, fmt.Errorf("%s: %v", job.path, err))
+		}
+	})
+}
+
+// Finishes decoded assets on the main thread; call once per frame
+func (l *asyncLoader) update(renderer *sdl.Renderer, budget time.Duration) {
+	if l.queue == nil {
+		return
+	}
+	l.queue.drain(renderer, budget)
+}
+
+// Fraction of jobs finished, for a loading screen
+func (l *asyncLoader) progress() float64 {
+	if len(l.jobs) == 0 {
+		return 1
+	}
+	return float64(l.done) / float64(len(l.jobs))
+}
+
+func (l *asyncLoader) finished() bool {
+	return l.done == len(l.jobs)
+}
+
+// Stops loading. Assets being decoded right now are discarded once they
+// reach the main thread; keep calling update until finished.
+func (l *asyncLoader) cancel() {
+	l.cancelled.Store(true)
+}
+
+func (l *asyncLoader) texture(path string) *MyTexture {
+	return l.textures[path]
+}
+
+func (l *asyncLoader) sound(path string) *mix.Chunk {
+	return l.sounds[path]
+}
+
+// Waits for the workers, finishes what they posted and frees everything
+// that was loaded. Main thread only.
+func (l *asyncLoader) free(renderer *sdl.Renderer) {
+	l.cancel()
+	l.wg.Wait()
+	l.update(renderer, time.Hour)
+
+	for _, t := range l.textures {
+		t.free()
+	}
+	for _, chunk := range l.sounds {
+		chunk.Free()
+	}
+	l.textures = map[string]*MyTexture{}
+	l.sounds = map[string]*mix.Chunk{}
+}
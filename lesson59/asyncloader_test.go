@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Go doesn't expose goroutine ids, but stack traces start with them
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	id, err := strconv.ParseUint(string(buf[:bytes.IndexByte(buf, ' ')]), 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// Jobs that decode nothing, but record where their callbacks ran
+type fakeJobs struct {
+	mainID uint64
+
+	// Set by the test around update, so uploads can tell they run from the queue
+	draining atomic.Bool
+
+	decodes   atomic.Int32
+	uploads   atomic.Int32
+	discards  atomic.Int32
+	misplaced atomic.Int32
+}
+
+func (f *fakeJobs) add(l *asyncLoader, path string, decodeErr error) {
+	l.addJob(path, func() (func(ctx *mainContext) error, func(), error) {
+		f.decodes.Add(1)
+		if goroutineID() == f.mainID {
+			f.misplaced.Add(1)
+		}
+		if decodeErr != nil {
+			return nil, nil, decodeErr
+		}
+
+		upload := func(ctx *mainContext) error {
+			f.uploads.Add(1)
+			if goroutineID() != f.mainID || !f.draining.Load() {
+				f.misplaced.Add(1)
+			}
+			return nil
+		}
+		discard := func() {
+			f.discards.Add(1)
+		}
+		return upload, discard, nil
+	})
+}
+
+func (f *fakeJobs) update(l *asyncLoader, budget time.Duration) {
+	f.draining.Store(true)
+	l.update(nil, budget)
+	f.draining.Store(false)
+}
+
+func TestDecodeOnWorkersUploadOnMainQueue(t *testing.T) {
+	const jobs = 16
+	f := &fakeJobs{mainID: goroutineID()}
+	l := NewAsyncLoader(4)
+	for i := 0; i < jobs; i++ {
+		f.add(l, fmt.Sprintf("asset%d", i), nil)
+	}
+
+	l.start(0)
+	l.wg.Wait()
+	if n := f.decodes.Load(); n != jobs {
+		t.Fatalf("%d decodes, want %d", n, jobs)
+	}
+
+	// Everything is decoded, but nothing reaches the main thread until update
+	if n := f.uploads.Load(); n != 0 {
+		t.Errorf("%d uploads before update", n)
+	}
+	f.update(l, 0)
+	if n := f.uploads.Load(); n != 0 || l.progress() != 0 {
+		t.Errorf("%d uploads with no time budget, progress %v", n, l.progress())
+	}
+
+	f.update(l, time.Hour)
+	if !l.finished() {
+		t.Fatalf("not finished, progress %v", l.progress())
+	}
+	if n := f.uploads.Load(); n != jobs {
+		t.Errorf("%d uploads, want %d", n, jobs)
+	}
+	if n := f.misplaced.Load(); n != 0 {
+		t.Errorf("%d callbacks ran on the wrong goroutine", n)
+	}
+	if len(l.errors) != 0 {
+		t.Errorf("unexpected errors: %v", l.errors)
+	}
+}
+
+func TestCancelDiscardsDecodedAssets(t *testing.T) {
+	f := &fakeJobs{mainID: goroutineID()}
+	l := NewAsyncLoader(2)
+	f.add(l, "good1", nil)
+	f.add(l, "bad", errors.New("corrupt"))
+	f.add(l, "good2", nil)
+
+	l.start(0)
+	l.wg.Wait()
+
+	// Cancelled after decoding: nothing is uploaded, but errors still show
+	l.cancel()
+	f.update(l, time.Hour)
+	if !l.finished() {
+		t.Fatalf("not finished, progress %v", l.progress())
+	}
+	if n := f.uploads.Load(); n != 0 {
+		t.Errorf("%d uploads after cancel", n)
+	}
+	if n := f.discards.Load(); n != 2 {
+		t.Errorf("%d discards, want 2", n)
+	}
+	if len(l.errors) != 1 || l.errors[0].Error() != "bad: corrupt" {
+		t.Errorf("errors: got %v, want [bad: corrupt]", l.errors)
+	}
+	if n := f.misplaced.Load(); n != 0 {
+		t.Errorf("%d callbacks ran on the wrong goroutine", n)
+	}
+}
+
+func TestCancelSkipsPendingJobs(t *testing.T) {
+	f := &fakeJobs{mainID: goroutineID()}
+	l := NewAsyncLoader(1)
+	for i := 0; i < 4; i++ {
+		f.add(l, fmt.Sprintf("asset%d", i), nil)
+	}
+
+	// Cancelled before the workers start: no job decodes, all still count
+	l.cancel()
+	l.start(0)
+	l.wg.Wait()
+	f.update(l, time.Hour)
+	if n := f.decodes.Load(); n != 0 {
+		t.Errorf("%d decodes after cancel", n)
+	}
+	if !l.finished() {
+		t.Errorf("not finished, progress %v", l.progress())
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_mixer"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+	"runtime"
+	"time"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const TEXT_FONT_SIZE = 16
+
+// Time per frame spent creating textures from decoded images
+const UPLOAD_BUDGET = 4 * time.Millisecond
+
+const (
+	THUMB_WIDTH  = 140
+	THUMB_HEIGHT = 105
+)
+
+var IMAGE_PATHS = []string{
+	"assets/background.png",
+	"assets/foo.png",
+	"assets/fadein.png",
+	"assets/fadeout.png",
+	"assets/arrow.png",
+	"assets/button.png",
+	"assets/prompt.png",
+}
+
+var SOUND_PATHS = []string{
+	"assets/beat.wav",
+	"assets/high.wav",
+	"assets/medium.wav",
+	"assets/low.wav",
+	"assets/scratch.wav",
+}
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+// Loaded up front, as the loading screen needs it
+var gFont *ttf.Font
+
+var gLoader *asyncLoader
+
+var gDelayFlag = flag.Int("delay", 300, "milliseconds added to every decode, to watch the loading screen")
+var gWorkersFlag = flag.Int("workers", 2, "number of decoding goroutines")
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+// Creates a texture from an already decoded surface, which stays owned by
+// the caller. Must run on the main thread.
+func NewMyTextureFromSurface(renderer *sdl.Renderer, surface *sdl.Surface) (*MyTexture, error) {
+	texture, err := renderer.CreateTextureFromSurface(surface)
+	if err != nil {
+		return nil, err
+	}
+	return &MyTexture{renderer, texture, surface.W, surface.H}, nil
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+func (t *MyTexture) renderScaled(clip *sdl.Rect, dst *sdl.Rect) {
+	gRenderer.Copy(t.texture, clip, dst)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+// SDL calls must come from the thread that initialized it, so keep the main
+// goroutine on the main thread. Loader goroutines run on other threads.
+func init() {
+	runtime.LockOSThread()
+}
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init font system
+	err = ttf.Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init sound system
+	err = mix.OpenAudio(44100, mix.DEFAULT_FORMAT, 2, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+// Only what the loading screen needs is loaded synchronously
+func loadMedia() {
+	var err error
+	gFont, err = ttf.OpenFont("assets/lazy.ttf", TEXT_FONT_SIZE)
+	must(err)
+}
+
+// Starts loading everything else in the background
+func startLoading() {
+	gLoader = NewAsyncLoader(*gWorkersFlag)
+	for _, path := range IMAGE_PATHS {
+		gLoader.addTexture(path, nil)
+	}
+	for _, path := range SOUND_PATHS {
+		gLoader.addSound(path)
+	}
+	gLoader.start(time.Duration(*gDelayFlag) * time.Millisecond)
+}
+
+func close() {
+	gLoader.free(gRenderer)
+	gFont.Close()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	mix.Quit()
+	ttf.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func renderText(text string, x, y int32, color sdl.Color) {
+	t := NewTextMyTexture(gRenderer, text, gFont, color)
+	t.render(x, y, nil)
+	t.free()
+}
+
+func renderLoadingScreen(cancelled bool) {
+	// Progress bar
+	bar := sdl.Rect{120, SCREEN_HEIGHT/2 - 10, SCREEN_WIDTH - 240, 20}
+	filled := bar
+	filled.W = int32(float64(bar.W) * gLoader.progress())
+	gRenderer.SetDrawColor(0, 128, 255, 255)
+	gRenderer.FillRect(&filled)
+	gRenderer.SetDrawColor(0, 0, 0, 255)
+	gRenderer.DrawRect(&bar)
+
+	status := fmt.Sprintf("Loading %d%%  (Esc: cancel)", int(gLoader.progress()*100))
+	if cancelled {
+		status = "Cancelling..."
+	}
+	renderText(status, bar.X, bar.Y-TEXT_FONT_SIZE-8, sdl.Color{0, 0, 0, 255})
+}
+
+// Shows what was loaded as thumbnails, plus any errors
+func renderLoaded(cancelled bool) {
+	x, y := int32(10), int32(10)
+	for _, path := range IMAGE_PATHS {
+		t := gLoader.texture(path)
+		if t == nil {
+			continue
+		}
+		t.renderScaled(nil, &sdl.Rect{x, y, THUMB_WIDTH, THUMB_HEIGHT})
+		x += THUMB_WIDTH + 10
+		if x+THUMB_WIDTH > SCREEN_WIDTH {
+			x = 10
+			y += THUMB_HEIGHT + 10
+		}
+	}
+
+	y = SCREEN_HEIGHT - 4*(TEXT_FONT_SIZE+4) - 10
+	status := fmt.Sprintf("Loaded %d images, %d sounds", len(gLoader.textures), len(gLoader.sounds))
+	if cancelled {
+		status += " before cancelling"
+	}
+	renderText(status, 10, y, sdl.Color{0, 0, 0, 255})
+	renderText("1-5: play sounds  R: load again", 10, y+TEXT_FONT_SIZE+4, sdl.Color{0, 0, 0, 255})
+	for i, err := range gLoader.errors {
+		renderText(err.Error(), 10, y+int32(i+2)*(TEXT_FONT_SIZE+4), sdl.Color{200, 0, 0, 255})
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	flag.Parse()
+
+	var err error
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+	startLoading()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var cancelled bool
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_ESCAPE:
+					if !gLoader.finished() {
+						gLoader.cancel()
+						cancelled = true
+					}
+				case sdl.SCANCODE_R:
+					if gLoader.finished() {
+						gLoader.free(gRenderer)
+						startLoading()
+						cancelled = false
+					}
+				case sdl.SCANCODE_1, sdl.SCANCODE_2, sdl.SCANCODE_3, sdl.SCANCODE_4, sdl.SCANCODE_5:
+					if chunk := gLoader.sound(SOUND_PATHS[t.Keysym.Scancode-sdl.SCANCODE_1]); chunk != nil {
+						chunk.Play(-1, 0)
+					}
+				}
+			}
+		}
+
+		// Create textures for whatever the workers decoded
+		gLoader.update(gRenderer, UPLOAD_BUDGET)
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		if gLoader.finished() {
+			renderLoaded(cancelled)
+		} else {
+			renderLoadingScreen(cancelled)
+		}
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const WINDOW_TITLE = "SDL Tutorial"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gPainter *painter
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow(WINDOW_TITLE, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func close() {
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// A five-pointed star, concave, turned by angle radians
+func starPoints(cx, cy, outer, inner, angle float64) []point {
+	points := make([]point, 10)
+	for i := range points {
+		r := outer
+		if i%2 == 1 {
+			r = inner
+		}
+		a := angle + float64(i)*math.Pi/5 - math.Pi/2
+		points[i] = point{cx + r*math.Cos(a), cy + r*math.Sin(a)}
+	}
+	return points
+}
+
+func updateTitle() {
+	if gPainter.antialias {
+		gWindow.SetTitle(WINDOW_TITLE + " - Antialiasing:On (A to toggle)")
+	} else {
+		gWindow.SetTitle(WINDOW_TITLE + " - Antialiasing:Off (A to toggle)")
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	gPainter = NewPainter(gRenderer)
+	gPainter.setAntialias(true)
+	updateTitle()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				if t.Keysym.Scancode == sdl.SCANCODE_A {
+					gPainter.setAntialias(!gPainter.antialias)
+					updateTitle()
+				}
+			}
+		}
+
+		// Slowly turning shapes show the edges best
+		angle := float64(sdl.GetTicks()) / 2000
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// Circles and ellipses
+		gPainter.setColor(sdl.Color{255, 0, 0, 255})
+		gPainter.fillCircle(80, 80, 50)
+		gPainter.setColor(sdl.Color{0, 0, 0, 255})
+		gPainter.drawCircle(80, 80, 60, 3)
+		gPainter.setColor(sdl.Color{0, 160, 0, 255})
+		gPainter.fillEllipse(230, 80, 70, 35)
+		gPainter.setColor(sdl.Color{0, 0, 0, 255})
+		gPainter.drawEllipse(230, 80, 70, 35, 1)
+
+		// Arcs and a pie slice
+		start := angle * 180 / math.Pi
+		gPainter.setColor(sdl.Color{0, 0, 255, 255})
+		gPainter.drawArc(400, 80, 50, start, start+270, 8)
+		gPainter.setColor(sdl.Color{255, 160, 0, 255})
+		gPainter.fillPie(550, 80, 50, 30, 330)
+
+		// A concave star, filled and outlined
+		star := starPoints(100, 260, 80, 32, angle)
+		gPainter.setColor(sdl.Color{255, 220, 0, 255})
+		gPainter.fillPolygon(star)
+		gPainter.setColor(sdl.Color{120, 60, 0, 255})
+		gPainter.drawPolygon(star, 4)
+
+		// Rounded rects, one half transparent over the other
+		gPainter.setColor(sdl.Color{0, 128, 128, 255})
+		gPainter.fillRoundedRect(rect{220, 190, 160, 100}, 20)
+		gPainter.setColor(sdl.Color{128, 0, 128, 128})
+		gPainter.fillRoundedRect(rect{270, 230, 160, 100}, 40)
+		gPainter.setColor(sdl.Color{0, 0, 0, 255})
+		gPainter.drawRoundedRect(rect{220, 190, 160, 100}, 20, 2)
+
+		// Thick lines fanning out
+		gPainter.setColor(sdl.Color{64, 64, 64, 255})
+		for i := 0; i < 6; i++ {
+			a := angle + float64(i)*math.Pi/6
+			gPainter.drawThickLine(540, 260, 540+80*math.Cos(a), 260+80*math.Sin(a), float64(i+1))
+		}
+
+		// A Bezier curve pulled towards the mouse
+		mouseX, mouseY, _ := sdl.GetMouseState()
+		mouse := point{float64(mouseX), float64(mouseY)}
+		gPainter.setColor(sdl.Color{200, 0, 100, 255})
+		gPainter.drawBezier(point{40, 440}, mouse, point{400, 460}, point{600, 380}, 5)
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+	"sort"
+)
+
+/* ------------------------------ painter ------------------------------ */
+
+// A point with sub-pixel precision
+type point struct {
+	x, y float64
+}
+
+type rect struct {
+	x, y, w, h float64
+}
+
+// What the painter draws with; *sdl.Renderer has all of it
+type canvas interface {
+	GetViewport(rect *sdl.Rect)
+	SetDrawColor(r, g, b, a uint8) error
+	SetDrawBlendMode(bm sdl.BlendMode) error
+	FillRects(rects []sdl.Rect) error
+	DrawPoints(points []sdl.Point) error
+	DrawLines(points []sdl.Point) error
+}
+
+// Sub-scanlines per pixel row when anti-aliasing
+const AA_SUBSAMPLES = 4
+
+// Maximum distance between a curve and the line segments approximating it
+const FLATTEN_TOLERANCE = 0.25
+
+// Draws shapes beyond the renderer's rects and lines. Every shape is turned
+// into polygons, which are rasterized into spans and drawn with FillRects.
+// With anti-aliasing on, partly covered edge pixels are drawn as points with
+// the coverage as alpha, so the renderer blends them.
+type painter struct {
+	renderer canvas
+
+	color     sdl.Color
+	antialias bool
+
+	// Scratch buffers reused between shapes
+	edges     []edge
+	crossings []crossing
+	coverage  []float64
+	rects     []sdl.Rect
+	points    [256][]sdl.Point
+}
+
+func NewPainter(renderer canvas) *painter {
+	return &painter{renderer: renderer, color: sdl.Color{0, 0, 0, 255}}
+}
+
+func (p *painter) setColor(c sdl.Color) {
+	p.color = c
+}
+
+func (p *painter) setAntialias(enabled bool) {
+	p.antialias = enabled
+}
+
+/* ------------------------------ shapes ------------------------------ */
+
+func (p *painter) fillCircle(cx, cy, r float64) {
+	p.fillEllipse(cx, cy, r, r)
+}
+
+func (p *painter) drawCircle(cx, cy, r, width float64) {
+	p.drawEllipse(cx, cy, r, r, width)
+}
+
+func (p *painter) fillEllipse(cx, cy, rx, ry float64) {
+	p.fillContours([][]point{arcPoints(cx, cy, rx, ry, 0, 360)})
+}
+
+// Outlines are centered on the shape's edge
+func (p *painter) drawEllipse(cx, cy, rx, ry, width float64) {
+	if p.thin(width) {
+		p.drawPolyline(arcPoints(cx, cy, rx, ry, 0, 360), true)
+		return
+	}
+	outer := arcPoints(cx, cy, rx+width/2, ry+width/2, 0, 360)
+	inner := arcPoints(cx, cy, math.Max(rx-width/2, 0), math.Max(ry-width/2, 0), 0, 360)
+	p.fillContours([][]point{outer, reversed(inner)})
+}
+
+// Angles are in degrees, clockwise from the positive x axis as y points down
+func (p *painter) drawArc(cx, cy, r, start, end, width float64) {
+	if p.thin(width) {
+		p.drawPolyline(arcPoints(cx, cy, r, r, start, end), false)
+		return
+	}
+	outer := arcPoints(cx, cy, r+width/2, r+width/2, start, end)
+	inner := arcPoints(cx, cy, math.Max(r-width/2, 0), math.Max(r-width/2, 0), start, end)
+
+	// A full circle is a ring; joining the ends would leave a wedge out
+	if math.Abs(end-start) >= 360 {
+		p.fillContours([][]point{outer, reversed(inner)})
+		return
+	}
+	p.fillContours([][]point{append(outer, reversed(inner)...)})
+}
+
+// A filled arc, like a slice of pie
+func (p *painter) fillPie(cx, cy, r, start, end float64) {
+	points := append(arcPoints(cx, cy, r, r, start, end), point{cx, cy})
+	p.fillContours([][]point{points})
+}
+
+// Convex, concave and self-intersecting polygons are filled by the nonzero
+// winding rule
+func (p *painter) fillPolygon(points []point) {
+	p.fillContours([][]point{points})
+}
+
+func (p *painter) drawPolygon(points []point, width float64) {
+	p.stroke(points, true, width)
+}
+
+func (p *painter) fillRoundedRect(r rect, radius float64) {
+	p.fillContours([][]point{roundedRectPoints(r, radius)})
+}
+
+func (p *painter) drawRoundedRect(r rect, radius, width float64) {
+	if p.thin(width) {
+		p.drawPolyline(roundedRectPoints(r, radius), true)
+		return
+	}
+	h := width / 2
+	outer := roundedRectPoints(rect{r.x - h, r.y - h, r.w + 2*h, r.h + 2*h}, radius+width/2)
+	inner := roundedRectPoints(rect{r.x + h, r.y + h, r.w - 2*h, r.h - 2*h}, math.Max(radius-width/2, 0))
+	p.fillContours([][]point{outer, reversed(inner)})
+}
+
+func (p *painter) drawThickLine(x1, y1, x2, y2, width float64) {
+	p.stroke([]point{{x1, y1}, {x2, y2}}, false, width)
+}
+
+// Cubic Bezier curve from p0 to p3, pulled towards c1 and c2
+func (p *painter) drawBezier(p0, c1, c2, p3 point, width float64) {
+	// Control polygon length bounds the curve length
+	length := distance(p0, c1) + distance(c1, c2) + distance(c2, p3)
+	n := int(math.Max(math.Ceil(length/4), 8))
+
+	points := make([]point, n+1)
+	for i := 0; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		points[i] = point{
+			a*p0.x + b*c1.x + c*c2.x + d*p3.x,
+			a*p0.y + b*c1.y + c*c2.y + d*p3.y,
+		}
+	}
+	p.stroke(points, false, width)
+}
+
+/* ------------------------------ outlines ------------------------------ */
+
+// One pixel wide outlines without anti-aliasing are left to the renderer
+func (p *painter) thin(width float64) bool {
+	return !p.antialias && width <= 1
+}
+
+func (p *painter) drawPolyline(points []point, closed bool) {
+	line := make([]sdl.Point, 0, len(points)+1)
+	for _, pt := range points {
+		line = append(line, sdl.Point{int32(math.Floor(pt.x)), int32(math.Floor(pt.y))})
+	}
+	if closed && len(line) > 0 {
+		line = append(line, line[0])
+	}
+	p.renderer.SetDrawColor(p.color.R, p.color.G, p.color.B, p.color.A)
+	p.renderer.DrawLines(line)
+}
+
+// Strokes a polyline by filling a quad per segment and a disc per joint.
+// All contours wind the same way, so overlaps are filled only once.
+func (p *painter) stroke(points []point, closed bool, width float64) {
+	if p.thin(width) {
+		p.drawPolyline(points, closed)
+		return
+	}
+
+	segments := len(points) - 1
+	if closed {
+		segments = len(points)
+	}
+
+	var contours [][]point
+	h := width / 2
+	for i := 0; i < segments; i++ {
+		a, b := points[i], points[(i+1)%len(points)]
+		dx, dy := b.x-a.x, b.y-a.y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		nx, ny := -dy/length*h, dx/length*h
+		contours = append(contours, positive([]point{
+			{a.x + nx, a.y + ny}, {b.x + nx, b.y + ny},
+			{b.x - nx, b.y - ny}, {a.x - nx, a.y - ny},
+		}))
+	}
+
+	// Round joins; open lines get butt caps
+	first, last := 1, len(points)-1
+	if closed {
+		first, last = 0, len(points)
+	}
+	for i := first; i < last; i++ {
+		pt := points[i]
+		contours = append(contours, positive(arcPoints(pt.x, pt.y, h, h, 0, 360)))
+	}
+
+	p.fillContours(contours)
+}
+
+/* ------------------------------ rasterizer ------------------------------ */
+
+// A non-horizontal polygon edge with y0 < y1
+type edge struct {
+	x0, y0, x1, y1 float64
+
+	// +1 if the edge went down in the contour, -1 if up
+	dir int
+}
+
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// Fills the area where the contours' winding number is nonzero
+func (p *painter) fillContours(contours [][]point) {
+	p.edges = p.edges[:0]
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, contour := range contours {
+		for i := range contour {
+			a, b := contour[i], contour[(i+1)%len(contour)]
+			x0, y0, x1, y1 := a.x, a.y, b.x, b.y
+			minX, maxX = math.Min(minX, x0), math.Max(maxX, x0)
+			minY, maxY = math.Min(minY, y0), math.Max(maxY, y0)
+			if y0 == y1 {
+				continue
+			}
+			if y0 < y1 {
+				p.edges = append(p.edges, edge{x0, y0, x1, y1, 1})
+			} else {
+				p.edges = append(p.edges, edge{x1, y1, x0, y0, -1})
+			}
+		}
+	}
+	if len(p.edges) == 0 {
+		return
+	}
+
+	// Only rasterize what is inside the viewport
+	var viewport sdl.Rect
+	p.renderer.GetViewport(&viewport)
+	left := int32(math.Max(math.Floor(minX), 0))
+	right := int32(math.Min(math.Ceil(maxX), float64(viewport.W)))
+	top := int32(math.Max(math.Floor(minY), 0))
+	bottom := int32(math.Min(math.Ceil(maxY), float64(viewport.H)))
+	if left >= right || top >= bottom {
+		return
+	}
+
+	p.rects = p.rects[:0]
+	if p.antialias {
+		p.rasterizeAA(left, right, top, bottom)
+	} else {
+		p.rasterize(top, bottom)
+	}
+	p.flush()
+}
+
+// Fills the pixels whose centers are inside
+func (p *painter) rasterize(top, bottom int32) {
+	for y := top; y < bottom; y++ {
+		p.spans(float64(y)+0.5, func(a, b float64) {
+			x0, x1 := int32(math.Ceil(a-0.5)), int32(math.Ceil(b-0.5))
+			if x1 > x0 {
+				p.rects = append(p.rects, sdl.Rect{x0, y, x1 - x0, 1})
+			}
+		})
+	}
+}
+
+// Samples AA_SUBSAMPLES scanlines per row and measures the exact horizontal
+// coverage of each, which averages to the pixel's coverage
+func (p *painter) rasterizeAA(left, right, top, bottom int32) {
+	width := int(right - left)
+	if cap(p.coverage) < width+1 {
+		p.coverage = make([]float64, width+1)
+	}
+	coverage := p.coverage[:width+1]
+
+	for y := top; y < bottom; y++ {
+		for i := range coverage {
+			coverage[i] = 0
+		}
+		for s := 0; s < AA_SUBSAMPLES; s++ {
+			sy := float64(y) + (float64(s)+0.5)/AA_SUBSAMPLES
+			p.spans(sy, func(a, b float64) {
+				accumulate(coverage[:width], a-float64(left), b-float64(left), 1.0/AA_SUBSAMPLES)
+			})
+		}
+
+		// Full pixels become rects, partial ones points
+		for x := 0; x < width; {
+			c := coverage[x]
+			if c >= 0.999 {
+				start := x
+				for x < width && coverage[x] >= 0.999 {
+					x++
+				}
+				p.rects = append(p.rects, sdl.Rect{left + int32(start), y, int32(x - start), 1})
+				continue
+			}
+			if alpha := uint8(c * float64(p.color.A)); alpha > 0 {
+				p.points[alpha] = append(p.points[alpha], sdl.Point{left + int32(x), y})
+			}
+			x++
+		}
+	}
+}
+
+// Calls f with the inside spans of the scanline at y, left to right
+func (p *painter) spans(y float64, f func(a, b float64)) {
+	p.crossings = p.crossings[:0]
+	for _, e := range p.edges {
+		if y < e.y0 || y >= e.y1 {
+			continue
+		}
+		x := e.x0 + (y-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+		p.crossings = append(p.crossings, crossing{x, e.dir})
+	}
+	sort.Slice(p.crossings, func(i, j int) bool { return p.crossings[i].x < p.crossings[j].x })
+
+	winding := 0
+	var start float64
+	for _, c := range p.crossings {
+		if winding == 0 {
+			start = c.x
+		}
+		winding += c.dir
+		if winding == 0 && c.x > start {
+			f(start, c.x)
+		}
+	}
+}
+
+// Adds weight times the part of each pixel covered by [a, b)
+func accumulate(coverage []float64, a, b, weight float64) {
+	a = math.Max(a, 0)
+	b = math.Min(b, float64(len(coverage)))
+	if a >= b {
+		return
+	}
+	ia, ib := int(a), int(b)
+	if ia == ib {
+		coverage[ia] += (b - a) * weight
+		return
+	}
+	coverage[ia] += (float64(ia+1) - a) * weight
+	for i := ia + 1; i < ib; i++ {
+		coverage[i] += weight
+	}
+	if ib < len(coverage) {
+		coverage[ib] += (b - float64(ib)) * weight
+	}
+}
+
+// Draws the collected rects and points, blending the partial ones
+func (p *painter) flush() {
+	p.renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+
+	if len(p.rects) > 0 {
+		p.renderer.SetDrawColor(p.color.R, p.color.G, p.color.B, p.color.A)
+		p.renderer.FillRects(p.rects)
+	}
+	for alpha := range p.points {
+		if len(p.points[alpha]) == 0 {
+			continue
+		}
+		p.renderer.SetDrawColor(p.color.R, p.color.G, p.color.B, uint8(alpha))
+		p.renderer.DrawPoints(p.points[alpha])
+		p.points[alpha] = p.points[alpha][:0]
+	}
+}
+
+/* ------------------------------ paths ------------------------------ */
+
+// Points along an elliptic arc, enough to stay within FLATTEN_TOLERANCE
+func arcPoints(cx, cy, rx, ry, start, end float64) []point {
+	r := math.Max(math.Max(rx, ry), FLATTEN_TOLERANCE)
+	step := 2 * math.Acos(math.Max(1-FLATTEN_TOLERANCE/r, -1))
+	sweep := (end - start) * math.Pi / 180
+	n := int(math.Max(math.Ceil(math.Abs(sweep)/step), 4))
+
+	closed := math.Abs(end-start) >= 360
+	count := n + 1
+	if closed {
+		// The last point would repeat the first
+		count = n
+	}
+
+	points := make([]point, count)
+	for i := range points {
+		angle := start*math.Pi/180 + sweep*float64(i)/float64(n)
+		points[i] = point{cx + rx*math.Cos(angle), cy + ry*math.Sin(angle)}
+	}
+	return points
+}
+
+func roundedRectPoints(r rect, radius float64) []point {
+	radius = math.Min(radius, math.Min(r.w, r.h)/2)
+	x0, y0 := r.x+radius, r.y+radius
+	x1, y1 := r.x+r.w-radius, r.y+r.h-radius
+
+	var points []point
+	points = append(points, arcPoints(x1, y0, radius, radius, 270, 360)...)
+	points = append(points, arcPoints(x1, y1, radius, radius, 0, 90)...)
+	points = append(points, arcPoints(x0, y1, radius, radius, 90, 180)...)
+	points = append(points, arcPoints(x0, y0, radius, radius, 180, 270)...)
+	return points
+}
+
+func reversed(points []point) []point {
+	r := make([]point, len(points))
+	for i, pt := range points {
+		r[len(points)-1-i] = pt
+	}
+	return r
+}
+
+// Makes the contour wind clockwise on screen, so contours of a stroke add up
+func positive(points []point) []point {
+	var area float64
+	for i := range points {
+		a, b := points[i], points[(i+1)%len(points)]
+		area += a.x*b.y - b.x*a.y
+	}
+	if area < 0 {
+		return reversed(points)
+	}
+	return points
+}
+
+func distance(a, b point) float64 {
+	return math.Hypot(b.x-a.x, b.y-a.y)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"github.com/veandco/go-sdl2/sdl"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var gUpdate = flag.Bool("update", false, "rewrite the golden images in testdata")
+
+// Largest difference in coverage, out of 255, that still matches a golden
+// image; floating point may round an edge pixel differently on other machines
+const GOLDEN_TOLERANCE = 2
+
+// Draws into a grayscale coverage mask instead of a renderer, blending the
+// way BLENDMODE_BLEND does, so the painter's output can be checked headless
+type maskCanvas struct {
+	mask  *image.Gray
+	alpha uint8
+}
+
+func NewMaskCanvas(width, height int) *maskCanvas {
+	return &maskCanvas{mask: image.NewGray(image.Rect(0, 0, width, height))}
+}
+
+func (c *maskCanvas) GetViewport(rect *sdl.Rect) {
+	*rect = sdl.Rect{0, 0, int32(c.mask.Rect.Dx()), int32(c.mask.Rect.Dy())}
+}
+
+// Only alpha matters for coverage
+func (c *maskCanvas) SetDrawColor(r, g, b, a uint8) error {
+	c.alpha = a
+	return nil
+}
+
+func (c *maskCanvas) SetDrawBlendMode(bm sdl.BlendMode) error {
+	return nil
+}
+
+func (c *maskCanvas) FillRects(rects []sdl.Rect) error {
+	for _, r := range rects {
+		for y := r.Y; y < r.Y+r.H; y++ {
+			for x := r.X; x < r.X+r.W; x++ {
+				c.blend(int(x), int(y))
+			}
+		}
+	}
+	return nil
+}
+
+func (c *maskCanvas) DrawPoints(points []sdl.Point) error {
+	for _, pt := range points {
+		c.blend(int(pt.X), int(pt.Y))
+	}
+	return nil
+}
+
+// Only one pixel wide outlines without anti-aliasing draw lines
+func (c *maskCanvas) DrawLines(points []sdl.Point) error {
+	return nil
+}
+
+func (c *maskCanvas) blend(x, y int) {
+	if !(image.Point{x, y}).In(c.mask.Rect) {
+		return
+	}
+	d := float64(c.mask.GrayAt(x, y).Y)
+	a := float64(c.alpha)
+	c.mask.SetGray(x, y, color.Gray{uint8(math.Round(a + d*(255-a)/255))})
+}
+
+// Five points joined every second one; the pentagon in the middle is wound
+// twice, so the nonzero rule fills it
+func pentagramPoints(cx, cy, r float64) []point {
+	points := make([]point, 5)
+	for i := range points {
+		a := float64(i*2)*2*math.Pi/5 - math.Pi/2
+		points[i] = point{cx + r*math.Cos(a), cy + r*math.Sin(a)}
+	}
+	return points
+}
+
+func paint(width, height int, draw func(p *painter)) *image.Gray {
+	c := NewMaskCanvas(width, height)
+	p := NewPainter(c)
+	p.setColor(sdl.Color{0, 0, 0, 255})
+	p.setAntialias(true)
+	draw(p)
+	return c.mask
+}
+
+func checkGolden(t *testing.T, name string, got *image.Gray) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".png")
+	if *gUpdate {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := png.Encode(f, got); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.Bounds() != got.Bounds() {
+		t.Fatalf("%s: size %v, golden is %v", name, got.Bounds(), want.Bounds())
+	}
+
+	var wrong, worst int
+	for y := got.Rect.Min.Y; y < got.Rect.Max.Y; y++ {
+		for x := got.Rect.Min.X; x < got.Rect.Max.X; x++ {
+			w := int(color.GrayModel.Convert(want.At(x, y)).(color.Gray).Y)
+			diff := int(got.GrayAt(x, y).Y) - w
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > GOLDEN_TOLERANCE {
+				wrong++
+			}
+			worst = max(worst, diff)
+		}
+	}
+	if wrong > 0 {
+		t.Errorf("%s: %d pixels differ from %s, by up to %d", name, wrong, path, worst)
+	}
+}
+
+func coverageAt(mask *image.Gray, x, y int) uint8 {
+	return mask.GrayAt(x, y).Y
+}
+
+func TestFillEllipse(t *testing.T) {
+	mask := paint(64, 48, func(p *painter) {
+		p.fillEllipse(32, 24, 28, 16)
+	})
+	checkGolden(t, "ellipse", mask)
+
+	if c := coverageAt(mask, 32, 24); c != 255 {
+		t.Errorf("center coverage %d, want 255", c)
+	}
+	if c := coverageAt(mask, 2, 2); c != 0 {
+		t.Errorf("corner coverage %d, want 0", c)
+	}
+}
+
+func TestDrawFullCircleArc(t *testing.T) {
+	mask := paint(64, 64, func(p *painter) {
+		p.drawArc(32, 32, 22, 0, 360, 6)
+	})
+	checkGolden(t, "arc", mask)
+
+	// The ring is closed where it starts and ends, and hollow
+	if c := coverageAt(mask, 54, 32); c != 255 {
+		t.Errorf("coverage where the arc starts %d, want 255", c)
+	}
+	if c := coverageAt(mask, 32, 32); c != 0 {
+		t.Errorf("center coverage %d, want 0", c)
+	}
+	if c := coverageAt(mask, 43, 32); c != 0 {
+		t.Errorf("coverage inside the ring %d, want 0", c)
+	}
+}
+
+func TestFillSelfIntersectingStar(t *testing.T) {
+	mask := paint(64, 64, func(p *painter) {
+		p.fillPolygon(pentagramPoints(32, 34, 30))
+	})
+	checkGolden(t, "star", mask)
+
+	if c := coverageAt(mask, 32, 34); c != 255 {
+		t.Errorf("center coverage %d, want 255", c)
+	}
+}
+
+func TestFillRoundedRect(t *testing.T) {
+	mask := paint(64, 48, func(p *painter) {
+		p.fillRoundedRect(rect{6, 8, 52, 32}, 10)
+	})
+	checkGolden(t, "rounded_rect", mask)
+
+	// The corner is cut off, the edge next to it isn't
+	if c := coverageAt(mask, 6, 8); c != 0 {
+		t.Errorf("corner coverage %d, want 0", c)
+	}
+	if c := coverageAt(mask, 6, 24); c != 255 {
+		t.Errorf("edge coverage %d, want 255", c)
+	}
+}
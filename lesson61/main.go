@@ -0,0 +1,376 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+// The dimensions of the level
+const (
+	LEVEL_WIDTH  = 1280
+	LEVEL_HEIGHT = 960
+)
+
+// Size of the checkerboard squares the level is made of
+const TILE_SIZE = 80
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 4
+
+const TEXT_FONT_SIZE = 16
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+var gFont *ttf.Font
+
+var gViewports *viewportManager
+
+// Up, down, left and right of every player
+var gPlayerKeys = [MAX_PANES][4]sdl.Scancode{
+	{sdl.SCANCODE_UP, sdl.SCANCODE_DOWN, sdl.SCANCODE_LEFT, sdl.SCANCODE_RIGHT},
+	{sdl.SCANCODE_W, sdl.SCANCODE_S, sdl.SCANCODE_A, sdl.SCANCODE_D},
+	{sdl.SCANCODE_I, sdl.SCANCODE_K, sdl.SCANCODE_J, sdl.SCANCODE_L},
+	{sdl.SCANCODE_KP_8, sdl.SCANCODE_KP_5, sdl.SCANCODE_KP_4, sdl.SCANCODE_KP_6},
+}
+
+var gPlayerColors = [MAX_PANES]sdl.Color{
+	{255, 64, 64, 255},
+	{64, 160, 255, 255},
+	{64, 200, 64, 255},
+	{255, 200, 0, 255},
+}
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y       int32
+	velX, velY int32
+
+	// Up, down, left and right
+	keys [4]sdl.Scancode
+
+	color sdl.Color
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case d.keys[0]:
+				d.velY -= DOT_VEL
+			case d.keys[1]:
+				d.velY += DOT_VEL
+			case d.keys[2]:
+				d.velX -= DOT_VEL
+			case d.keys[3]:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case d.keys[0]:
+				d.velY += DOT_VEL
+			case d.keys[1]:
+				d.velY -= DOT_VEL
+			case d.keys[2]:
+				d.velX += DOT_VEL
+			case d.keys[3]:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+func (d *dot) move() {
+	// Move the dot left or right
+	d.x += d.velX
+
+	// If the dot went too far to the left or right
+	if d.x < 0 || d.x+DOT_WIDTH > LEVEL_WIDTH {
+		d.x -= d.velX
+	}
+
+	// Move the dot up or down
+	d.y += d.velY
+
+	// If the dot went too far to the up or down
+	if d.y < 0 || d.y+DOT_HEIGHT > LEVEL_HEIGHT {
+		d.y -= d.velY
+	}
+}
+
+// Render the dot relative to the camera
+func (d *dot) render(camera *sdl.Rect) {
+	gDotTexture.setColor(d.color.R, d.color.G, d.color.B)
+	gDotTexture.render(d.x-camera.X, d.y-camera.Y, nil)
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create a resizable window; the panes follow its size
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init font system
+	err = ttf.Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+
+	var err error
+	gFont, err = ttf.OpenFont("assets/lazy.ttf", TEXT_FONT_SIZE)
+	must(err)
+}
+
+func close() {
+	gDotTexture.free()
+	gFont.Close()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	ttf.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Draws the visible part of the checkerboard level
+func renderLevel(camera *sdl.Rect) {
+	gRenderer.SetDrawColor(255, 255, 255, 255)
+	gRenderer.FillRect(&sdl.Rect{0, 0, camera.W, camera.H})
+
+	gRenderer.SetDrawColor(220, 220, 220, 255)
+	for row := camera.Y / TILE_SIZE; row*TILE_SIZE < camera.Y+camera.H && row < LEVEL_HEIGHT/TILE_SIZE; row++ {
+		for col := camera.X / TILE_SIZE; col*TILE_SIZE < camera.X+camera.W && col < LEVEL_WIDTH/TILE_SIZE; col++ {
+			if (row+col)%2 == 0 {
+				gRenderer.FillRect(&sdl.Rect{col*TILE_SIZE - camera.X, row*TILE_SIZE - camera.Y, TILE_SIZE, TILE_SIZE})
+			}
+		}
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	// One dot per possible player, spread over the level
+	var dots [MAX_PANES]dot
+	for i := range dots {
+		dots[i] = dot{
+			x:     int32(200 + i%2*800),
+			y:     int32(200 + i/2*500),
+			keys:  gPlayerKeys[i],
+			color: gPlayerColors[i],
+		}
+	}
+
+	players := 2
+	gViewports = NewViewportManager(SCREEN_WIDTH, SCREEN_HEIGHT, players)
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.WindowEvent:
+				if t.Event == sdl.WINDOWEVENT_SIZE_CHANGED {
+					gViewports.resize(t.Data1, t.Data2)
+				}
+			case *sdl.KeyDownEvent:
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_1, sdl.SCANCODE_2, sdl.SCANCODE_3, sdl.SCANCODE_4:
+					// Players join and leave; their panes split and merge
+					players = int(t.Keysym.Scancode-sdl.SCANCODE_1) + 1
+					gViewports.setPaneCount(players)
+
+					// Players who left miss their key releases
+					for i := players; i < MAX_PANES; i++ {
+						dots[i].velX, dots[i].velY = 0, 0
+					}
+				case sdl.SCANCODE_H:
+					gViewports.setSplitHorizontal(!gViewports.splitHorizontal)
+				case sdl.SCANCODE_B:
+					if gViewports.border > 0 {
+						gViewports.setBorder(0)
+					} else {
+						gViewports.setBorder(4)
+					}
+				}
+			}
+
+			for i := 0; i < players; i++ {
+				dots[i].handleEvent(event)
+			}
+		}
+
+		// Move the dots and the cameras following them
+		for i := 0; i < players; i++ {
+			dots[i].move()
+			gViewports.panes[i].follow(dots[i].x+DOT_WIDTH/2, dots[i].y+DOT_HEIGHT/2, LEVEL_WIDTH, LEVEL_HEIGHT)
+		}
+
+		gViewports.render(gRenderer,
+			func(index int, p *pane) {
+				renderLevel(&p.camera)
+				for i := 0; i < players; i++ {
+					dots[i].render(&p.camera)
+				}
+			},
+			func(index int, p *pane) {
+				// Player label and a frame in the player's color
+				c := gPlayerColors[index]
+				gRenderer.SetDrawColor(c.R, c.G, c.B, c.A)
+				gRenderer.DrawRect(&sdl.Rect{0, 0, p.viewport.W, p.viewport.H})
+				label := NewTextMyTexture(gRenderer, fmt.Sprintf("P%d", index+1), gFont, sdl.Color{0, 0, 0, 255})
+				label.render(8, 6, nil)
+				label.free()
+			})
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/* ------------------------------ viewport manager ------------------------------ */
+
+const MAX_PANES = 4
+
+// A part of the window showing the level through its own camera
+type pane struct {
+	// Where the pane is in the window
+	viewport sdl.Rect
+
+	// The part of the level shown; always the size of the viewport
+	camera sdl.Rect
+}
+
+// Centers the camera on a point and keeps it inside the level. A level
+// smaller than the pane stays at the pane's top left.
+func (p *pane) follow(x, y, levelWidth, levelHeight int32) {
+	p.camera.X = x - p.camera.W/2
+	p.camera.Y = y - p.camera.H/2
+
+	if p.camera.X > levelWidth-p.camera.W {
+		p.camera.X = levelWidth - p.camera.W
+	}
+	if p.camera.Y > levelHeight-p.camera.H {
+		p.camera.Y = levelHeight - p.camera.H
+	}
+	if p.camera.X < 0 {
+		p.camera.X = 0
+	}
+	if p.camera.Y < 0 {
+		p.camera.Y = 0
+	}
+}
+
+// Splits the window into one to four panes, recomputed whenever the number
+// of panes or the window size changes:
+//
+//	1: whole window
+//	2: side by side, or one above the other if splitHorizontal
+//	3: two on top, one across the bottom
+//	4: two by two
+type viewportManager struct {
+	width  int32
+	height int32
+
+	panes []*pane
+
+	// Gap between panes, filled with the border color
+	border      int32
+	borderColor sdl.Color
+
+	splitHorizontal bool
+}
+
+func NewViewportManager(width, height int32, count int) *viewportManager {
+	m := &viewportManager{
+		width:       width,
+		height:      height,
+		border:      4,
+		borderColor: sdl.Color{0, 0, 0, 255},
+	}
+	m.setPaneCount(count)
+	return m
+}
+
+// Splits or merges panes. Existing panes keep their index, so player n
+// keeps pane n.
+func (m *viewportManager) setPaneCount(count int) {
+	if count < 1 {
+		count = 1
+	}
+	if count > MAX_PANES {
+		count = MAX_PANES
+	}
+	for len(m.panes) < count {
+		m.panes = append(m.panes, &pane{})
+	}
+	m.panes = m.panes[:count]
+	m.layout()
+}
+
+func (m *viewportManager) resize(width, height int32) {
+	m.width = width
+	m.height = height
+	m.layout()
+}
+
+func (m *viewportManager) setBorder(border int32) {
+	m.border = border
+	m.layout()
+}
+
+func (m *viewportManager) setSplitHorizontal(horizontal bool) {
+	m.splitHorizontal = horizontal
+	m.layout()
+}
+
+func (m *viewportManager) layout() {
+	// Sizes of the left/top and right/bottom halves, with the border between
+	leftW := (m.width - m.border) / 2
+	rightX := leftW + m.border
+	rightW := m.width - rightX
+	topH := (m.height - m.border) / 2
+	bottomY := topH + m.border
+	bottomH := m.height - bottomY
+
+	var rects []sdl.Rect
+	switch len(m.panes) {
+	case 1:
+		rects = []sdl.Rect{{0, 0, m.width, m.height}}
+	case 2:
+		if m.splitHorizontal {
+			rects = []sdl.Rect{{0, 0, m.width, topH}, {0, bottomY, m.width, bottomH}}
+		} else {
+			rects = []sdl.Rect{{0, 0, leftW, m.height}, {rightX, 0, rightW, m.height}}
+		}
+	case 3:
+		rects = []sdl.Rect{{0, 0, leftW, topH}, {rightX, 0, rightW, topH}, {0, bottomY, m.width, bottomH}}
+	case 4:
+		rects = []sdl.Rect{
+			{0, 0, leftW, topH}, {rightX, 0, rightW, topH},
+			{0, bottomY, leftW, bottomH}, {rightX, bottomY, rightW, bottomH},
+		}
+	}
+
+	for i, p := range m.panes {
+		p.viewport = rects[i]
+		p.camera.W = rects[i].W
+		p.camera.H = rects[i].H
+	}
+}
+
+// Draws every pane: scene in camera coordinates, then overlay in pane
+// coordinates, both clipped to the pane. The window is filled with the
+// border color first, so the gaps between panes become the borders.
+func (m *viewportManager) render(renderer *sdl.Renderer, scene, overlay func(index int, p *pane)) {
+	renderer.SetDrawColor(m.borderColor.R, m.borderColor.G, m.borderColor.B, m.borderColor.A)
+	renderer.Clear()
+
+	for i, p := range m.panes {
+		renderer.SetViewport(&p.viewport)
+		renderer.SetClipRect(&sdl.Rect{0, 0, p.viewport.W, p.viewport.H})
+		scene(i, p)
+		if overlay != nil {
+			overlay(i, p)
+		}
+	}
+	renderer.SetClipRect(nil)
+	renderer.SetViewport(nil)
+}
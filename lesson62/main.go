@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+// The dimensions of the level, tiled with the background
+const (
+	LEVEL_WIDTH  = 1920
+	LEVEL_HEIGHT = 1440
+)
+
+// Camera speed in pixels per frame
+const CAMERA_VEL = 8
+
+const WINDOW_TITLE = "SDL Tutorial"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gBackgroundTexture *MyTexture
+var gArrowTexture *MyTexture
+var gFooTexture *MyTexture
+var gDotTexture *MyTexture
+
+var gScene *scene
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+// Render stretched to fill dst, turned around center, which is relative to
+// dst. Scene nodes compute all of these from their transform.
+func (t *MyTexture) renderScaledRotationFlip(clip *sdl.Rect, dst *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) {
+	gRenderer.CopyEx(t.texture, clip, dst, angle, center, flip)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow(WINDOW_TITLE, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gBackgroundTexture = NewMyTexture(gRenderer, "assets/background.png", nil)
+	gArrowTexture = NewMyTexture(gRenderer, "assets/arrow.png", nil)
+	gFooTexture = NewMyTexture(gRenderer, "assets/foo.png", &sdl.Color{0, 255, 255, 0})
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+}
+
+func close() {
+	gBackgroundTexture.free()
+	gArrowTexture.free()
+	gFooTexture.free()
+	gDotTexture.free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	// Background tiles below everything, most of them off-screen at any time
+	gScene = NewScene()
+	for y := int32(0); y < LEVEL_HEIGHT; y += gBackgroundTexture.height {
+		for x := int32(0); x < LEVEL_WIDTH; x += gBackgroundTexture.width {
+			tile := NewSpriteNode("background", gBackgroundTexture, nil)
+			tile.pivotX, tile.pivotY = 0, 0
+			tile.setPosition(float64(x), float64(y))
+			tile.z = -10
+			gScene.root.addChild(tile)
+		}
+	}
+
+	// foo stands in the middle of the level
+	foo := gScene.root.addChild(NewSpriteNode("foo", gFooTexture, nil))
+	foo.setPosition(LEVEL_WIDTH/2, LEVEL_HEIGHT/2)
+
+	// An invisible pivot turning around foo carries the arrow, which in turn
+	// carries two dots; every child inherits the turning of its parents
+	orbit := gScene.root.addChild(NewNode("orbit"))
+	orbit.setPosition(LEVEL_WIDTH/2, LEVEL_HEIGHT/2)
+
+	arrow := orbit.addChild(NewSpriteNode("arrow", gArrowTexture, nil))
+	arrow.setPosition(160, 0)
+	arrow.setScale(0.3, 0.3)
+	arrow.z = 1
+
+	var moons []*node
+	for i := 0; i < 2; i++ {
+		// Positions are in the arrow's space and shrink with it; undo the
+		// arrow's scale so the dots keep their size
+		moon := arrow.addChild(NewSpriteNode("moon", gDotTexture, nil))
+		moon.setPosition(float64(400-i*800), 0)
+		moon.setScale(1/0.3, 1/0.3)
+		moons = append(moons, moon)
+	}
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	// The camera area, starting over foo
+	camera := &sdl.Rect{(LEVEL_WIDTH - SCREEN_WIDTH) / 2, (LEVEL_HEIGHT - SCREEN_HEIGHT) / 2, SCREEN_WIDTH, SCREEN_HEIGHT}
+	var camVelX, camVelY int32
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				if t.Repeat != 0 {
+					break
+				}
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_UP:
+					camVelY -= CAMERA_VEL
+				case sdl.SCANCODE_DOWN:
+					camVelY += CAMERA_VEL
+				case sdl.SCANCODE_LEFT:
+					camVelX -= CAMERA_VEL
+				case sdl.SCANCODE_RIGHT:
+					camVelX += CAMERA_VEL
+				case sdl.SCANCODE_Z:
+					// Pass the arrow behind or in front of foo
+					arrow.z = -arrow.z
+				case sdl.SCANCODE_F:
+					// A negative scale mirrors foo
+					foo.local.scaleX = -foo.local.scaleX
+				case sdl.SCANCODE_H:
+					// Hides the arrow and its moons with it
+					arrow.visible = !arrow.visible
+				}
+			case *sdl.KeyUpEvent:
+				if t.Repeat != 0 {
+					break
+				}
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_UP:
+					camVelY += CAMERA_VEL
+				case sdl.SCANCODE_DOWN:
+					camVelY -= CAMERA_VEL
+				case sdl.SCANCODE_LEFT:
+					camVelX += CAMERA_VEL
+				case sdl.SCANCODE_RIGHT:
+					camVelX -= CAMERA_VEL
+				}
+			}
+		}
+
+		// Move the camera, keeping it inside the level
+		camera.X += camVelX
+		camera.Y += camVelY
+		if camera.X < 0 || camera.X > LEVEL_WIDTH-camera.W {
+			camera.X -= camVelX
+		}
+		if camera.Y < 0 || camera.Y > LEVEL_HEIGHT-camera.H {
+			camera.Y -= camVelY
+		}
+
+		// Animate the local transforms only; world transforms follow
+		seconds := float64(sdl.GetTicks()) / 1000
+		orbit.local.angle = seconds * 45
+		arrow.local.angle = seconds * 90
+		for _, moon := range moons {
+			moon.local.angle = -seconds * 180
+		}
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		gScene.render(camera)
+
+		// Update screen
+		gRenderer.Present()
+
+		gWindow.SetTitle(fmt.Sprintf("%s - drawn %d, culled %d (arrows: camera, Z, F, H)",
+			WINDOW_TITLE, gScene.drawn, gScene.culled))
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
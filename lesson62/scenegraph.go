@@ -0,0 +1,243 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+	"sort"
+)
+
+/* ------------------------------ transforms ------------------------------ */
+
+// Position, rotation in degrees and scale. Rotating a non-uniformly scaled
+// parent would skew its children, which SDL can't draw, so scale is applied
+// along the child's own axes instead.
+type transform struct {
+	x, y           float64
+	angle          float64
+	scaleX, scaleY float64
+}
+
+func identityTransform() transform {
+	return transform{scaleX: 1, scaleY: 1}
+}
+
+// Maps a point from the local space of t to its parent's space
+func (t transform) apply(x, y float64) (float64, float64) {
+	x *= t.scaleX
+	y *= t.scaleY
+	sin, cos := math.Sincos(t.angle * math.Pi / 180)
+	return t.x + x*cos - y*sin, t.y + x*sin + y*cos
+}
+
+// The world transform of a child with the given local transform
+func (t transform) combine(local transform) transform {
+	x, y := t.apply(local.x, local.y)
+
+	// A mirrored parent turns its children the other way
+	angle := local.angle
+	if t.scaleX*t.scaleY < 0 {
+		angle = -angle
+	}
+	return transform{
+		x:      x,
+		y:      y,
+		angle:  t.angle + angle,
+		scaleX: t.scaleX * local.scaleX,
+		scaleY: t.scaleY * local.scaleY,
+	}
+}
+
+/* ------------------------------ nodes ------------------------------ */
+
+// An element of the scene. A node without a texture only groups and moves
+// its children.
+type node struct {
+	name string
+
+	// Relative to the parent
+	local transform
+
+	// Higher z is drawn later, on top; equal z keeps tree order
+	z int
+
+	// Hides the node and all its children
+	visible bool
+
+	texture *MyTexture
+	clip    *sdl.Rect
+	flip    sdl.RendererFlip
+
+	// Point of the texture, in texture pixels, placed at the node's position
+	// and rotated around
+	pivotX, pivotY float64
+
+	parent   *node
+	children []*node
+
+	// Computed by the scene every frame
+	world transform
+}
+
+func NewNode(name string) *node {
+	return &node{name: name, local: identityTransform(), visible: true}
+}
+
+// A node drawing the texture, pivoting around its center
+func NewSpriteNode(name string, texture *MyTexture, clip *sdl.Rect) *node {
+	n := NewNode(name)
+	n.texture = texture
+	n.clip = clip
+	w, h := n.size()
+	n.pivotX, n.pivotY = w/2, h/2
+	return n
+}
+
+func (n *node) addChild(child *node) *node {
+	if child.parent != nil {
+		child.parent.removeChild(child)
+	}
+	child.parent = n
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *node) removeChild(child *node) {
+	for i, c := range n.children {
+		if c == child {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			child.parent = nil
+			return
+		}
+	}
+}
+
+func (n *node) setPosition(x, y float64) {
+	n.local.x, n.local.y = x, y
+}
+
+func (n *node) setScale(scaleX, scaleY float64) {
+	n.local.scaleX, n.local.scaleY = scaleX, scaleY
+}
+
+// Unscaled size of what the node draws
+func (n *node) size() (float64, float64) {
+	if n.clip != nil {
+		return float64(n.clip.W), float64(n.clip.H)
+	}
+	if n.texture != nil {
+		return float64(n.texture.width), float64(n.texture.height)
+	}
+	return 0, 0
+}
+
+// World-space corners of the rotated, scaled texture, clockwise from its
+// top left
+func (n *node) corners() [4][2]float64 {
+	w, h := n.size()
+	corners := [4][2]float64{{0, 0}, {w, 0}, {w, h}, {0, h}}
+	for i, corner := range corners {
+		corners[i][0], corners[i][1] = n.world.apply(corner[0]-n.pivotX, corner[1]-n.pivotY)
+	}
+	return corners
+}
+
+// World-space bounding box of the rotated, scaled texture
+func (n *node) bounds() sdl.Rect {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range n.corners() {
+		x, y := corner[0], corner[1]
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	return sdl.Rect{int32(math.Floor(minX)), int32(math.Floor(minY)),
+		int32(math.Ceil(maxX - minX)), int32(math.Ceil(maxY - minY))}
+}
+
+// Draws the texture with the world transform, relative to the camera.
+// Negative scales mirror the texture.
+func (n *node) render(camera *sdl.Rect) {
+	w, h := n.size()
+	scaleX, scaleY := n.world.scaleX, n.world.scaleY
+	flip := n.flip
+	if scaleX < 0 {
+		scaleX = -scaleX
+		flip ^= sdl.FLIP_HORIZONTAL
+	}
+	if scaleY < 0 {
+		scaleY = -scaleY
+		flip ^= sdl.FLIP_VERTICAL
+	}
+
+	// Where the pivot ends up, from the quad's top left corner
+	pivotX, pivotY := n.pivotX*scaleX, n.pivotY*scaleY
+	if flip&sdl.FLIP_HORIZONTAL != 0 {
+		pivotX = w*scaleX - pivotX
+	}
+	if flip&sdl.FLIP_VERTICAL != 0 {
+		pivotY = h*scaleY - pivotY
+	}
+
+	// Rounded so that the pivot lands on the rounded node position, which
+	// keeps children from jittering against their parents
+	center := &sdl.Point{int32(math.Round(pivotX)), int32(math.Round(pivotY))}
+	dst := &sdl.Rect{
+		int32(math.Round(n.world.x-float64(camera.X))) - center.X,
+		int32(math.Round(n.world.y-float64(camera.Y))) - center.Y,
+		int32(math.Round(w * scaleX)),
+		int32(math.Round(h * scaleY)),
+	}
+	n.texture.renderScaledRotationFlip(n.clip, dst, n.world.angle, center, flip)
+}
+
+/* ------------------------------ scene ------------------------------ */
+
+type scene struct {
+	root *node
+
+	// Visible textured nodes in draw order, rebuilt every frame
+	drawList []*node
+
+	// Statistics of the last render
+	drawn  int
+	culled int
+}
+
+func NewScene() *scene {
+	return &scene{root: NewNode("root")}
+}
+
+// Computes world transforms, sorts by z and draws the nodes overlapping
+// the camera
+func (s *scene) render(camera *sdl.Rect) {
+	s.drawList = s.drawList[:0]
+	s.collect(s.root, identityTransform())
+
+	sort.SliceStable(s.drawList, func(i, j int) bool {
+		return s.drawList[i].z < s.drawList[j].z
+	})
+
+	s.drawn, s.culled = 0, 0
+	for _, n := range s.drawList {
+		bounds := n.bounds()
+		if !bounds.HasIntersection(camera) {
+			s.culled++
+			continue
+		}
+		n.render(camera)
+		s.drawn++
+	}
+}
+
+func (s *scene) collect(n *node, parent transform) {
+	if !n.visible {
+		return
+	}
+	n.world = parent.combine(n.local)
+	if n.texture != nil {
+		s.drawList = append(s.drawList, n)
+	}
+	for _, child := range n.children {
+		s.collect(child, n.world)
+	}
+}
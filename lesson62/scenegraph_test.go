@@ -0,0 +1,87 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func checkCorners(t *testing.T, n *node, want [4][2]float64) {
+	t.Helper()
+	for i, corner := range n.corners() {
+		if !near(corner[0], want[i][0]) || !near(corner[1], want[i][1]) {
+			t.Errorf("%s corner %d: got %v, want %v", n.name, i, corner, want[i])
+		}
+	}
+}
+
+// A mirrored parent turns its children the other way
+func TestMirroredParent(t *testing.T) {
+	s := NewScene()
+	parent := s.root.addChild(NewNode("parent"))
+	parent.setPosition(100, 100)
+	parent.setScale(-1, 1)
+
+	child := parent.addChild(NewSpriteNode("child", nil, &sdl.Rect{0, 0, 20, 10}))
+	child.setPosition(10, 0)
+	child.local.angle = 90
+
+	s.collect(s.root, identityTransform())
+
+	if !near(child.world.angle, -90) {
+		t.Errorf("world angle: got %v, want -90", child.world.angle)
+	}
+	if !near(child.world.x, 90) || !near(child.world.y, 100) {
+		t.Errorf("world position: got %v, %v, want 90, 100", child.world.x, child.world.y)
+	}
+
+	// Turned a quarter clockwise around (10, 0), then mirrored around x = 0
+	checkCorners(t, child, [4][2]float64{{85, 90}, {85, 110}, {95, 110}, {95, 90}})
+}
+
+// World transforms place corners where applying every local transform in
+// turn does, mirrored or not
+func TestWorldTransformMatchesLocalChain(t *testing.T) {
+	for _, c := range []struct {
+		name           string
+		scaleX, scaleY float64
+	}{
+		{"plain", 1, 1},
+		{"mirrored horizontally", -1, 1},
+		{"mirrored vertically", 2, -2},
+		{"turned upside down", -1, -1},
+	} {
+		s := NewScene()
+		parent := s.root.addChild(NewNode("parent"))
+		parent.setPosition(320, 240)
+		parent.local.angle = 30
+		parent.setScale(c.scaleX, c.scaleY)
+
+		child := parent.addChild(NewNode("child"))
+		child.setPosition(40, -15)
+		child.local.angle = 25
+
+		leaf := child.addChild(NewSpriteNode("leaf", nil, &sdl.Rect{0, 0, 24, 16}))
+		leaf.setPosition(-8, 12)
+		leaf.local.angle = -70
+		leaf.setScale(1, -1)
+
+		s.collect(s.root, identityTransform())
+
+		var want [4][2]float64
+		for i, corner := range [4][2]float64{{0, 0}, {24, 0}, {24, 16}, {0, 16}} {
+			x, y := leaf.local.apply(corner[0]-leaf.pivotX, corner[1]-leaf.pivotY)
+			x, y = child.local.apply(x, y)
+			want[i][0], want[i][1] = parent.local.apply(x, y)
+		}
+		for i, corner := range leaf.corners() {
+			if !near(corner[0], want[i][0]) || !near(corner[1], want[i][1]) {
+				t.Errorf("%s: corner %d: got %v, want %v", c.name, i, corner, want[i])
+			}
+		}
+	}
+}
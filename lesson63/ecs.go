@@ -0,0 +1,371 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_mixer"
+	"sort"
+)
+
+/* ------------------------------ entities ------------------------------ */
+
+// An entity is only an id; everything it is made of lives in the component
+// stores. Ids are never reused, so a destroyed entity simply has no
+// components anymore.
+type entity uint32
+
+/* ------------------------------ component storage ------------------------------ */
+
+// What the world needs to know about a store of any component type
+type storage interface {
+	has(e entity) bool
+	remove(e entity)
+	owners() []entity
+}
+
+// Components of one type, packed in a slice so systems walk them in memory
+// order. Pointers returned by add and get stay valid until the next add or
+// remove on the same store.
+type componentStore[T any] struct {
+	dense    []T
+	entities []entity
+
+	// Index into dense of every entity's component
+	index map[entity]int
+}
+
+// Creates a store and registers it with the world, so destroying an entity
+// also removes its component of this type
+func NewComponentStore[T any](w *world) *componentStore[T] {
+	s := &componentStore[T]{index: make(map[entity]int)}
+	w.stores = append(w.stores, s)
+	return s
+}
+
+// Adds the component to the entity, replacing the one it may already have
+func (s *componentStore[T]) add(e entity, c T) *T {
+	if i, ok := s.index[e]; ok {
+		s.dense[i] = c
+		return &s.dense[i]
+	}
+	s.index[e] = len(s.dense)
+	s.dense = append(s.dense, c)
+	s.entities = append(s.entities, e)
+	return &s.dense[len(s.dense)-1]
+}
+
+// The entity's component, or nil if it has none
+func (s *componentStore[T]) get(e entity) *T {
+	if i, ok := s.index[e]; ok {
+		return &s.dense[i]
+	}
+	return nil
+}
+
+func (s *componentStore[T]) has(e entity) bool {
+	_, ok := s.index[e]
+	return ok
+}
+
+// Moves the last component into the hole, keeping the slice packed
+func (s *componentStore[T]) remove(e entity) {
+	i, ok := s.index[e]
+	if !ok {
+		return
+	}
+	last := len(s.dense) - 1
+	s.dense[i] = s.dense[last]
+	s.entities[i] = s.entities[last]
+	s.index[s.entities[i]] = i
+
+	var zero T
+	s.dense[last] = zero
+	s.dense = s.dense[:last]
+	s.entities = s.entities[:last]
+	delete(s.index, e)
+}
+
+func (s *componentStore[T]) owners() []entity {
+	return s.entities
+}
+
+/* ------------------------------ built-in components ------------------------------ */
+
+// Top left position in the world
+type transform struct {
+	x, y float64
+}
+
+// Pixels per frame
+type velocity struct {
+	x, y float64
+}
+
+type sprite struct {
+	texture *MyTexture
+
+	// Part of the texture to draw, the whole texture if nil
+	clip *sdl.Rect
+
+	// Higher layers are drawn later, on top
+	layer int
+
+	visible bool
+}
+
+// An axis-aligned box, relative to the transform. Moving entities with a
+// collider are kept inside the world bounds.
+type collider struct {
+	box sdl.Rect
+
+	// Set by the movement system when the last move was stopped by a bound
+	blocked bool
+}
+
+// Frames of a sprite sheet. With a zero frameTicks the animation doesn't
+// advance by itself and another system picks the frame.
+type animation struct {
+	frames     []sdl.Rect
+	frame      int
+	frameTicks uint32
+	loop       bool
+
+	// When the current frame was shown
+	started uint32
+}
+
+// Plays its chunk once every time play is set
+type audioEmitter struct {
+	chunk *mix.Chunk
+	play  bool
+}
+
+/* ------------------------------ world ------------------------------ */
+
+// When a system runs: every frame, update systems run in the order they were
+// added, then render systems do, also in order
+const (
+	PHASE_UPDATE = iota
+	PHASE_RENDER
+	PHASE_TOTAL
+)
+
+type system struct {
+	name string
+	run  func(w *world)
+}
+
+type world struct {
+	renderer *sdl.Renderer
+
+	// Area moving colliders can't leave
+	bounds sdl.Rect
+
+	// Events polled this frame, for the systems that handle input
+	events []sdl.Event
+
+	next      entity
+	alive     map[entity]bool
+	destroyed []entity
+
+	stores  []storage
+	systems [PHASE_TOTAL][]system
+
+	// Built-in component stores
+	transforms    *componentStore[transform]
+	velocities    *componentStore[velocity]
+	sprites       *componentStore[sprite]
+	colliders     *componentStore[collider]
+	animations    *componentStore[animation]
+	audioEmitters *componentStore[audioEmitter]
+
+	// Scratch list the render system sorts
+	drawList []entity
+}
+
+func NewWorld(renderer *sdl.Renderer, bounds sdl.Rect) *world {
+	w := &world{
+		renderer: renderer,
+		bounds:   bounds,
+		next:     1,
+		alive:    make(map[entity]bool),
+	}
+	w.transforms = NewComponentStore[transform](w)
+	w.velocities = NewComponentStore[velocity](w)
+	w.sprites = NewComponentStore[sprite](w)
+	w.colliders = NewComponentStore[collider](w)
+	w.animations = NewComponentStore[animation](w)
+	w.audioEmitters = NewComponentStore[audioEmitter](w)
+	return w
+}
+
+func (w *world) create() entity {
+	e := w.next
+	w.next++
+	w.alive[e] = true
+	return e
+}
+
+// Destroys the entity at the end of the frame, so systems iterating over it
+// aren't pulled from under their feet
+func (w *world) destroy(e entity) {
+	if w.alive[e] {
+		w.destroyed = append(w.destroyed, e)
+	}
+}
+
+func (w *world) isAlive(e entity) bool {
+	return w.alive[e]
+}
+
+func (w *world) addSystem(phase int, name string, run func(w *world)) {
+	w.systems[phase] = append(w.systems[phase], system{name, run})
+}
+
+// Entities having a component in every one of the stores, in creation order.
+// The result is a new slice, so systems may add and remove components while
+// walking it.
+func (w *world) query(stores ...storage) []entity {
+	if len(stores) == 0 {
+		return nil
+	}
+
+	// Walk the smallest store and check the others
+	smallest := stores[0]
+	for _, s := range stores[1:] {
+		if len(s.owners()) < len(smallest.owners()) {
+			smallest = s
+		}
+	}
+
+	var result []entity
+	for _, e := range smallest.owners() {
+		matches := true
+		for _, s := range stores {
+			if !s.has(e) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			result = append(result, e)
+		}
+	}
+
+	// Removals reorder the packed stores; creation order doesn't change
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// Runs every update system on the events of the frame
+func (w *world) update(events []sdl.Event) {
+	w.events = events
+	for _, s := range w.systems[PHASE_UPDATE] {
+		s.run(w)
+	}
+	w.events = nil
+
+	// Entities destroyed during the frame
+	for _, e := range w.destroyed {
+		for _, s := range w.stores {
+			s.remove(e)
+		}
+		delete(w.alive, e)
+	}
+	w.destroyed = w.destroyed[:0]
+}
+
+func (w *world) render() {
+	for _, s := range w.systems[PHASE_RENDER] {
+		s.run(w)
+	}
+}
+
+/* ------------------------------ built-in systems ------------------------------ */
+
+// Moves entities by their velocity one axis at a time. An entity with a
+// collider that would leave the bounds takes back the move on that axis.
+func movementSystem(w *world) {
+	for _, e := range w.query(w.transforms, w.velocities) {
+		t := w.transforms.get(e)
+		v := w.velocities.get(e)
+		c := w.colliders.get(e)
+		if c == nil {
+			t.x += v.x
+			t.y += v.y
+			continue
+		}
+
+		c.blocked = false
+
+		t.x += v.x
+		left := int32(t.x) + c.box.X
+		if left < w.bounds.X || left+c.box.W > w.bounds.X+w.bounds.W {
+			t.x -= v.x
+			c.blocked = true
+		}
+
+		t.y += v.y
+		top := int32(t.y) + c.box.Y
+		if top < w.bounds.Y || top+c.box.H > w.bounds.Y+w.bounds.H {
+			t.y -= v.y
+			c.blocked = true
+		}
+	}
+}
+
+// Advances timed animations and points the sprite at the current frame
+func animationSystem(w *world) {
+	now := sdl.GetTicks()
+	for _, e := range w.query(w.animations, w.sprites) {
+		a := w.animations.get(e)
+		if len(a.frames) == 0 {
+			continue
+		}
+
+		if a.frameTicks > 0 {
+			if a.started == 0 {
+				a.started = now
+			}
+			for now-a.started >= a.frameTicks {
+				a.started += a.frameTicks
+				if a.frame+1 < len(a.frames) {
+					a.frame++
+				} else if a.loop {
+					a.frame = 0
+				}
+			}
+		}
+
+		if a.frame >= len(a.frames) {
+			a.frame = len(a.frames) - 1
+		}
+		w.sprites.get(e).clip = &a.frames[a.frame]
+	}
+}
+
+func audioSystem(w *world) {
+	for _, e := range w.query(w.audioEmitters) {
+		a := w.audioEmitters.get(e)
+		if a.play {
+			a.play = false
+			a.chunk.Play(-1, 0)
+		}
+	}
+}
+
+// Draws visible sprites at their transform, by layer, then creation order
+func renderSystem(w *world) {
+	w.drawList = append(w.drawList[:0], w.query(w.transforms, w.sprites)...)
+	sort.SliceStable(w.drawList, func(i, j int) bool {
+		return w.sprites.get(w.drawList[i]).layer < w.sprites.get(w.drawList[j]).layer
+	})
+
+	for _, e := range w.drawList {
+		s := w.sprites.get(e)
+		if !s.visible {
+			continue
+		}
+		t := w.transforms.get(e)
+		s.texture.render(int32(t.x), int32(t.y), s.clip)
+	}
+}
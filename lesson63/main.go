@@ -0,0 +1,366 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_mixer"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 10
+
+const (
+	BUTTON_WIDTH  = 300
+	BUTTON_HEIGHT = 200
+)
+
+const BUTTON_NUM = 4
+
+const (
+	BUTTON_SPRITE_MOUSE_OUT = iota
+	BUTTON_SPRITE_MOUSE_OVER_MOTION
+	BUTTON_SPRITE_MOUSE_DOWN
+	BUTTON_SPRITE_MOUSE_UP
+	BUTTON_SPRITE_TOTAL
+)
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+var gButtonTexture *MyTexture
+
+// One sound per button
+var gButtonSounds [BUTTON_NUM]*mix.Chunk
+
+var gWorld *world
+
+// Components only this lesson uses, stored like the built-in ones
+var gControls *componentStore[keyboardControl]
+var gButtons *componentStore[button]
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+// Drives the velocity with the arrow keys, like the dot of lesson 26
+type keyboardControl struct {
+	speed float64
+}
+
+// Mouse state of a lesson 17 button; its animation frames are the states
+type button struct {
+	state int
+}
+
+// Adds or takes back speed on key presses and releases
+func controlSystem(w *world) {
+	for _, event := range w.events {
+		var sign float64
+		var scancode sdl.Scancode
+		switch t := event.(type) {
+		case *sdl.KeyDownEvent:
+			if t.Repeat != 0 {
+				continue
+			}
+			sign, scancode = 1, t.Keysym.Scancode
+		case *sdl.KeyUpEvent:
+			if t.Repeat != 0 {
+				continue
+			}
+			sign, scancode = -1, t.Keysym.Scancode
+		default:
+			continue
+		}
+
+		for _, e := range w.query(gControls, w.velocities) {
+			speed := sign * gControls.get(e).speed
+			v := w.velocities.get(e)
+			switch scancode {
+			case sdl.SCANCODE_UP:
+				v.y -= speed
+			case sdl.SCANCODE_DOWN:
+				v.y += speed
+			case sdl.SCANCODE_LEFT:
+				v.x -= speed
+			case sdl.SCANCODE_RIGHT:
+				v.x += speed
+			}
+		}
+	}
+}
+
+// Tests the mouse against the buttons' colliders. Pressing a button plays its
+// sound; the right button destroys it.
+func buttonSystem(w *world) {
+	for _, event := range w.events {
+		var x, y int32
+		var eventType uint32
+		var mouseButton uint8
+		switch t := event.(type) {
+		case *sdl.MouseMotionEvent:
+			x, y, eventType = t.X, t.Y, t.Type
+		case *sdl.MouseButtonEvent:
+			x, y, eventType, mouseButton = t.X, t.Y, t.Type, t.Button
+		default:
+			continue
+		}
+
+		for _, e := range w.query(gButtons, w.transforms, w.colliders, w.animations) {
+			t := w.transforms.get(e)
+			box := w.colliders.get(e).box
+			box.X += int32(t.x)
+			box.Y += int32(t.y)
+			b := gButtons.get(e)
+
+			if x < box.X || x >= box.X+box.W || y < box.Y || y >= box.Y+box.H {
+				b.state = BUTTON_SPRITE_MOUSE_OUT
+			} else {
+				switch eventType {
+				case sdl.MOUSEMOTION:
+					b.state = BUTTON_SPRITE_MOUSE_OVER_MOTION
+				case sdl.MOUSEBUTTONDOWN:
+					b.state = BUTTON_SPRITE_MOUSE_DOWN
+					if mouseButton == sdl.BUTTON_RIGHT {
+						w.destroy(e)
+					} else if emitter := w.audioEmitters.get(e); emitter != nil {
+						emitter.play = true
+					}
+				case sdl.MOUSEBUTTONUP:
+					b.state = BUTTON_SPRITE_MOUSE_UP
+				}
+			}
+			w.animations.get(e).frame = b.state
+		}
+	}
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init sound system
+	err = mix.OpenAudio(44100, mix.DEFAULT_FORMAT, 2, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+	gButtonTexture = NewMyTexture(gRenderer, "assets/button.png", nil)
+
+	var err error
+	for i, path := range []string{"assets/high.wav", "assets/medium.wav", "assets/low.wav", "assets/scratch.wav"} {
+		gButtonSounds[i], err = mix.LoadWAV(path)
+		must(err)
+	}
+}
+
+func close() {
+	gDotTexture.free()
+	gButtonTexture.free()
+	for _, chunk := range gButtonSounds {
+		chunk.Free()
+	}
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	mix.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// The dot of lesson 26, above the buttons
+func createDot(w *world) entity {
+	e := w.create()
+	w.transforms.add(e, transform{(SCREEN_WIDTH - DOT_WIDTH) / 2, (SCREEN_HEIGHT - DOT_HEIGHT) / 2})
+	w.velocities.add(e, velocity{})
+	w.sprites.add(e, sprite{texture: gDotTexture, layer: 1, visible: true})
+	w.colliders.add(e, collider{box: sdl.Rect{0, 0, DOT_WIDTH, DOT_HEIGHT}})
+	gControls.add(e, keyboardControl{speed: DOT_VEL})
+	return e
+}
+
+// The buttons of lesson 17, one in every corner
+func createButtons(w *world) {
+	corners := [BUTTON_NUM]sdl.Point{
+		{0, 0},
+		{SCREEN_WIDTH - BUTTON_WIDTH, 0},
+		{0, SCREEN_HEIGHT - BUTTON_HEIGHT},
+		{SCREEN_WIDTH - BUTTON_WIDTH, SCREEN_HEIGHT - BUTTON_HEIGHT},
+	}
+
+	var frames []sdl.Rect
+	for i := 0; i < BUTTON_SPRITE_TOTAL; i++ {
+		frames = append(frames, sdl.Rect{0, int32(i * BUTTON_HEIGHT), BUTTON_WIDTH, BUTTON_HEIGHT})
+	}
+
+	for i, corner := range corners {
+		e := w.create()
+		w.transforms.add(e, transform{float64(corner.X), float64(corner.Y)})
+		w.sprites.add(e, sprite{texture: gButtonTexture, visible: true})
+		w.colliders.add(e, collider{box: sdl.Rect{0, 0, BUTTON_WIDTH, BUTTON_HEIGHT}})
+		w.animations.add(e, animation{frames: frames})
+		w.audioEmitters.add(e, audioEmitter{chunk: gButtonSounds[i]})
+		gButtons.add(e, button{})
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	gWorld = NewWorld(gRenderer, sdl.Rect{0, 0, SCREEN_WIDTH, SCREEN_HEIGHT})
+	gControls = NewComponentStore[keyboardControl](gWorld)
+	gButtons = NewComponentStore[button](gWorld)
+
+	// Input first, so the frame moves and draws what was just pressed
+	gWorld.addSystem(PHASE_UPDATE, "control", controlSystem)
+	gWorld.addSystem(PHASE_UPDATE, "button", buttonSystem)
+	gWorld.addSystem(PHASE_UPDATE, "movement", movementSystem)
+	gWorld.addSystem(PHASE_UPDATE, "animation", animationSystem)
+	gWorld.addSystem(PHASE_UPDATE, "audio", audioSystem)
+	gWorld.addSystem(PHASE_RENDER, "render", renderSystem)
+
+	createButtons(gWorld)
+	createDot(gWorld)
+
+	var events []sdl.Event
+
+	var quit bool
+	for !quit {
+		events = events[:0]
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				// Bring back the buttons destroyed with the right mouse button
+				if t.Keysym.Scancode == sdl.SCANCODE_R && t.Repeat == 0 && len(gButtons.owners()) < BUTTON_NUM {
+					for _, e := range gWorld.query(gButtons) {
+						gWorld.destroy(e)
+					}
+					createButtons(gWorld)
+				}
+			}
+			events = append(events, event)
+		}
+
+		gWorld.update(events)
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		gWorld.render()
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
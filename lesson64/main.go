@@ -0,0 +1,531 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 10
+
+const TEXT_FONT_SIZE = 24
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+var gFont *ttf.Font
+
+var gStates *stateManager
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y       int32
+	velX, velY int32
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX -= DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX += DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+// Sets the velocity from the arrow keys held right now. Key presses and
+// releases made while another state had the input never reached the dot.
+func (d *dot) syncWithKeyboard() {
+	keys := sdl.GetKeyboardState()
+	d.velX, d.velY = 0, 0
+	if keys[sdl.SCANCODE_UP] != 0 {
+		d.velY -= DOT_VEL
+	}
+	if keys[sdl.SCANCODE_DOWN] != 0 {
+		d.velY += DOT_VEL
+	}
+	if keys[sdl.SCANCODE_LEFT] != 0 {
+		d.velX -= DOT_VEL
+	}
+	if keys[sdl.SCANCODE_RIGHT] != 0 {
+		d.velX += DOT_VEL
+	}
+}
+
+func (d *dot) move() {
+	// Move the dot left or right
+	d.x += d.velX
+
+	// If the dot went too far to the left or right
+	if d.x < 0 || d.x+DOT_WIDTH > SCREEN_WIDTH {
+		d.x -= d.velX
+	}
+
+	// Move the dot up or down
+	d.y += d.velY
+
+	// If the dot went too far to the up or down
+	if d.y < 0 || d.y+DOT_HEIGHT > SCREEN_HEIGHT {
+		d.y -= d.velY
+	}
+}
+
+func (d *dot) render() {
+	gDotTexture.render(d.x, d.y, nil)
+}
+
+// Press Enter to play
+type titleState struct {
+	baseState
+}
+
+func (s *titleState) handleEvent(e sdl.Event) {
+	if t, ok := e.(*sdl.KeyDownEvent); ok && t.Repeat == 0 {
+		switch t.Keysym.Scancode {
+		case sdl.SCANCODE_RETURN:
+			gStates.replace(&gameplayState{}, transition{TRANSITION_SLIDE_LEFT, 400})
+		case sdl.SCANCODE_ESCAPE:
+			// Popping the last state quits
+			gStates.pop(transition{TRANSITION_FADE, 300})
+		}
+	}
+}
+
+func (s *titleState) render() {
+	gRenderer.SetDrawColor(32, 48, 96, 255)
+	gRenderer.Clear()
+	renderTextCentered("DOT GAME", SCREEN_HEIGHT/3, sdl.Color{255, 255, 255, 255})
+	renderTextCentered("Enter to play, Esc to quit", SCREEN_HEIGHT/2, sdl.Color{160, 180, 255, 255})
+}
+
+// The dot of lesson 26. Frames only count while the game is updated, which
+// stops under the pause menu.
+type gameplayState struct {
+	baseState
+
+	dot    dot
+	frames int
+}
+
+func (s *gameplayState) enter() {
+	s.dot.syncWithKeyboard()
+}
+
+func (s *gameplayState) resume() {
+	s.dot.syncWithKeyboard()
+}
+
+func (s *gameplayState) handleEvent(e sdl.Event) {
+	if t, ok := e.(*sdl.KeyDownEvent); ok && t.Repeat == 0 {
+		if t.Keysym.Scancode == sdl.SCANCODE_ESCAPE || t.Keysym.Scancode == sdl.SCANCODE_P {
+			gStates.push(&pauseState{}, transition{TRANSITION_SLIDE_DOWN, 250})
+			return
+		}
+	}
+	s.dot.handleEvent(e)
+}
+
+func (s *gameplayState) update() {
+	s.dot.move()
+	s.frames++
+}
+
+func (s *gameplayState) render() {
+	gRenderer.SetDrawColor(255, 255, 255, 255)
+	gRenderer.Clear()
+	s.dot.render()
+	renderText(fmt.Sprintf("Frames played: %d (Esc to pause)", s.frames), 10, 10, sdl.Color{0, 0, 0, 255})
+}
+
+const (
+	PAUSE_RESUME = iota
+	PAUSE_QUIT
+	PAUSE_ITEM_TOTAL
+)
+
+// A menu over the darkened game
+type pauseState struct {
+	baseState
+
+	selected int
+}
+
+func (s *pauseState) isOverlay() bool {
+	return true
+}
+
+func (s *pauseState) handleEvent(e sdl.Event) {
+	t, ok := e.(*sdl.KeyDownEvent)
+	if !ok || t.Repeat != 0 {
+		return
+	}
+
+	switch t.Keysym.Scancode {
+	case sdl.SCANCODE_UP:
+		s.selected = (s.selected + PAUSE_ITEM_TOTAL - 1) % PAUSE_ITEM_TOTAL
+	case sdl.SCANCODE_DOWN:
+		s.selected = (s.selected + 1) % PAUSE_ITEM_TOTAL
+	case sdl.SCANCODE_ESCAPE, sdl.SCANCODE_P:
+		gStates.pop(transition{TRANSITION_SLIDE_DOWN, 250})
+	case sdl.SCANCODE_RETURN:
+		if s.selected == PAUSE_RESUME {
+			gStates.pop(transition{TRANSITION_SLIDE_DOWN, 250})
+		} else {
+			gStates.push(&confirmState{question: "Quit to the title screen?", onYes: quitToTitle}, transition{TRANSITION_FADE, 150})
+		}
+	}
+}
+
+func (s *pauseState) render() {
+	gRenderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	gRenderer.SetDrawColor(0, 0, 0, 160)
+	gRenderer.FillRect(&sdl.Rect{0, 0, SCREEN_WIDTH, SCREEN_HEIGHT})
+
+	renderTextCentered("PAUSED", SCREEN_HEIGHT/3, sdl.Color{255, 255, 255, 255})
+	for i, item := range [PAUSE_ITEM_TOTAL]string{"Resume", "Quit to title"} {
+		color := sdl.Color{160, 160, 160, 255}
+		if i == s.selected {
+			color = sdl.Color{255, 220, 0, 255}
+		}
+		renderTextCentered(item, SCREEN_HEIGHT/2+int32(i)*40, color)
+	}
+}
+
+// A yes/no question in a box, over whatever is below
+type confirmState struct {
+	baseState
+
+	question string
+	onYes    func()
+}
+
+func (s *confirmState) isOverlay() bool {
+	return true
+}
+
+func (s *confirmState) handleEvent(e sdl.Event) {
+	t, ok := e.(*sdl.KeyDownEvent)
+	if !ok || t.Repeat != 0 {
+		return
+	}
+
+	switch t.Keysym.Scancode {
+	case sdl.SCANCODE_Y, sdl.SCANCODE_RETURN:
+		s.onYes()
+	case sdl.SCANCODE_N, sdl.SCANCODE_ESCAPE:
+		gStates.pop(transition{TRANSITION_FADE, 150})
+	}
+}
+
+func (s *confirmState) render() {
+	box := sdl.Rect{SCREEN_WIDTH/2 - 180, SCREEN_HEIGHT/2 - 60, 360, 120}
+	gRenderer.SetDrawColor(64, 64, 64, 255)
+	gRenderer.FillRect(&box)
+	gRenderer.SetDrawColor(255, 255, 255, 255)
+	gRenderer.DrawRect(&box)
+
+	renderTextCentered(s.question, box.Y+20, sdl.Color{255, 255, 255, 255})
+	renderTextCentered("Y / N", box.Y+70, sdl.Color{255, 220, 0, 255})
+}
+
+// Takes off the question and the pause menu, then swaps the game for the
+// title screen. Queued operations run one after the other.
+func quitToTitle() {
+	gStates.pop(transition{})
+	gStates.pop(transition{})
+	gStates.replace(&titleState{}, transition{TRANSITION_FADE, 500})
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+// Creates a texture that can be drawn into with pushRenderTarget
+func NewTargetMyTexture(renderer *sdl.Renderer, width, height int32) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.createBlank(width, height, sdl.TEXTUREACCESS_TARGET)
+
+	// Keep transparent parts transparent when drawn onto something else
+	t.setBlendMode(sdl.BLENDMODE_BLEND)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) createBlank(width, height int32, access int) {
+	// Free pre-existing texture
+	t.free()
+
+	var err error
+	t.texture, err = t.renderer.CreateTexture(sdl.PIXELFORMAT_RGBA8888, access, int(width), int(height))
+	must(err)
+
+	t.width = width
+	t.height = height
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ render targets ------------------------------ */
+
+// Textures currently drawn into, innermost last. Every push must be
+// matched by a pop; the window is the target once the stack is empty.
+var gTargetStack []*MyTexture
+
+// Make the texture the target of all drawing until popRenderTarget
+func (t *MyTexture) pushRenderTarget() {
+	must(t.renderer.SetRenderTarget(t.texture))
+	gTargetStack = append(gTargetStack, t)
+}
+
+// Go back to drawing into the previous target
+func popRenderTarget() {
+	top := gTargetStack[len(gTargetStack)-1]
+	gTargetStack = gTargetStack[:len(gTargetStack)-1]
+
+	var previous *sdl.Texture
+	if len(gTargetStack) > 0 {
+		previous = gTargetStack[len(gTargetStack)-1].texture
+	}
+	must(top.renderer.SetRenderTarget(previous))
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer; transitions draw into render targets
+	renderer, err := sdl.CreateRenderer(window, -1,
+		sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC|sdl.RENDERER_TARGETTEXTURE)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init font system
+	err = ttf.Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+
+	var err error
+	gFont, err = ttf.OpenFont("assets/lazy.ttf", TEXT_FONT_SIZE)
+	must(err)
+}
+
+func close() {
+	gStates.clear()
+	gStates.free()
+
+	gDotTexture.free()
+	gFont.Close()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	ttf.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func renderText(text string, x, y int32, color sdl.Color) {
+	textTexture := NewTextMyTexture(gRenderer, text, gFont, color)
+	textTexture.render(x, y, nil)
+	textTexture.free()
+}
+
+// Renders the text centered horizontally
+func renderTextCentered(text string, y int32, color sdl.Color) {
+	textTexture := NewTextMyTexture(gRenderer, text, gFont, color)
+	textTexture.render((SCREEN_WIDTH-textTexture.width)/2, y, nil)
+	textTexture.free()
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	if !gRenderer.RenderTargetSupported() {
+		panic("render targets are not supported")
+	}
+
+	loadMedia()
+
+	gStates = NewStateManager(gRenderer, SCREEN_WIDTH, SCREEN_HEIGHT)
+	gStates.push(&titleState{}, transition{TRANSITION_FADE, 500})
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	// Runs until the window is closed or the title screen pops itself
+	var quit bool
+	for !quit && !gStates.empty() {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			}
+
+			gStates.handleEvent(event)
+		}
+
+		gStates.update()
+
+		// Clear screen, in case nothing below the states is opaque
+		gRenderer.SetDrawColor(0, 0, 0, 255)
+		gRenderer.Clear()
+
+		gStates.render()
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/* ------------------------------ game states ------------------------------ */
+
+// A screen of the game with its own input, logic and drawing: title screen,
+// gameplay, a menu. The state manager keeps them on a stack; only the top one
+// gets events and updates.
+type gameState interface {
+	// Put on the stack, by push or replace
+	enter()
+
+	// Taken off the stack, by pop or replace
+	exit()
+
+	// Another state was pushed on top
+	pause()
+
+	// The state on top was popped
+	resume()
+
+	handleEvent(e sdl.Event)
+	update()
+	render()
+
+	// An overlay only covers part of the screen or is translucent, so the
+	// states below it keep being drawn, but not updated
+	isOverlay() bool
+}
+
+// Hooks doing nothing, to embed in states that don't need them all
+type baseState struct{}
+
+func (baseState) enter()                  {}
+func (baseState) exit()                   {}
+func (baseState) pause()                  {}
+func (baseState) resume()                 {}
+func (baseState) handleEvent(e sdl.Event) {}
+func (baseState) update()                 {}
+func (baseState) render()                 {}
+func (baseState) isOverlay() bool         { return false }
+
+/* ------------------------------ transitions ------------------------------ */
+
+const (
+	TRANSITION_NONE = iota
+
+	// The state fades in or out with setAlpha
+	TRANSITION_FADE
+
+	// The state comes in from the right edge and leaves the same way
+	TRANSITION_SLIDE_LEFT
+
+	// The state comes down from the top edge and leaves the same way
+	TRANSITION_SLIDE_DOWN
+)
+
+// How a state comes in or leaves; the zero value switches at once
+type transition struct {
+	kind int
+
+	// In milliseconds
+	duration uint32
+}
+
+// A state coming in or leaving, and what to do once it's done
+type stateAnimation struct {
+	state   gameState
+	trans   transition
+	leaving bool
+	start   uint32
+	finish  func()
+}
+
+// How much of the state shows, from 0 to 1
+func (a *stateAnimation) progress() float64 {
+	p := float64(sdl.GetTicks()-a.start) / float64(a.trans.duration)
+	if p > 1 {
+		p = 1
+	}
+	if a.leaving {
+		p = 1 - p
+	}
+	return p
+}
+
+func (a *stateAnimation) done() bool {
+	return sdl.GetTicks()-a.start >= a.trans.duration
+}
+
+/* ------------------------------ state manager ------------------------------ */
+
+type stateManager struct {
+	renderer *sdl.Renderer
+	width    int32
+	height   int32
+
+	// Bottom first
+	stack []gameState
+
+	// The transition running, if any. Events and updates wait for it.
+	animation *stateAnimation
+
+	// Operations asked for during a transition, run in order after it
+	pending []func()
+
+	// The animated state is drawn here first, then moved or faded
+	target *MyTexture
+}
+
+func NewStateManager(renderer *sdl.Renderer, width, height int32) *stateManager {
+	return &stateManager{
+		renderer: renderer,
+		width:    width,
+		height:   height,
+		target:   NewTargetMyTexture(renderer, width, height),
+	}
+}
+
+func (m *stateManager) top() gameState {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// True once the last state was popped; time to quit
+func (m *stateManager) empty() bool {
+	return len(m.stack) == 0 && m.animation == nil && len(m.pending) == 0
+}
+
+// Pauses the top state and puts s over it
+func (m *stateManager) push(s gameState, trans transition) {
+	m.run(func() {
+		if top := m.top(); top != nil {
+			top.pause()
+		}
+		m.stack = append(m.stack, s)
+		s.enter()
+		m.animate(s, trans, false, nil)
+	})
+}
+
+// Takes the top state off once it has left, and resumes the one below
+func (m *stateManager) pop(trans transition) {
+	m.run(func() {
+		s := m.top()
+		if s == nil {
+			return
+		}
+		m.animate(s, trans, true, func() {
+			m.stack = m.stack[:len(m.stack)-1]
+			s.exit()
+			if top := m.top(); top != nil {
+				top.resume()
+			}
+		})
+	})
+}
+
+// Puts s over the top state, which exits once s fully covers it. The states
+// below aren't paused or resumed.
+func (m *stateManager) replace(s gameState, trans transition) {
+	m.run(func() {
+		old := m.top()
+		m.stack = append(m.stack, s)
+		s.enter()
+		m.animate(s, trans, false, func() {
+			if old == nil {
+				return
+			}
+			m.stack = append(m.stack[:len(m.stack)-2], s)
+			old.exit()
+		})
+	})
+}
+
+// Exits every state, top first, without transitions
+func (m *stateManager) clear() {
+	m.animation = nil
+	m.pending = nil
+	for len(m.stack) > 0 {
+		s := m.top()
+		m.stack = m.stack[:len(m.stack)-1]
+		s.exit()
+	}
+}
+
+// Queues the operation behind the running transition, if any
+func (m *stateManager) run(op func()) {
+	m.pending = append(m.pending, op)
+	m.runPending()
+}
+
+func (m *stateManager) runPending() {
+	for m.animation == nil && len(m.pending) > 0 {
+		op := m.pending[0]
+		m.pending = m.pending[1:]
+		op()
+	}
+}
+
+func (m *stateManager) animate(s gameState, trans transition, leaving bool, finish func()) {
+	if trans.kind == TRANSITION_NONE || trans.duration == 0 {
+		if finish != nil {
+			finish()
+		}
+		return
+	}
+	m.animation = &stateAnimation{s, trans, leaving, sdl.GetTicks(), finish}
+}
+
+func (m *stateManager) handleEvent(e sdl.Event) {
+	if m.animation != nil {
+		return
+	}
+	if top := m.top(); top != nil {
+		top.handleEvent(e)
+	}
+}
+
+// Finishes the transition once its time is up, otherwise updates the top
+// state
+func (m *stateManager) update() {
+	if a := m.animation; a != nil {
+		if !a.done() {
+			return
+		}
+		m.animation = nil
+		if a.finish != nil {
+			a.finish()
+		}
+		m.runPending()
+		return
+	}
+
+	if top := m.top(); top != nil {
+		top.update()
+	}
+}
+
+// Draws the top state and the states showing through it, bottom first
+func (m *stateManager) render() {
+	// The highest opaque state hides everything below. A state being
+	// animated doesn't cover the screen, whatever it is.
+	first := 0
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		s := m.stack[i]
+		if !s.isOverlay() && (m.animation == nil || m.animation.state != s) {
+			first = i
+			break
+		}
+	}
+
+	for _, s := range m.stack[first:] {
+		if m.animation != nil && m.animation.state == s {
+			m.renderAnimated()
+		} else {
+			s.render()
+		}
+	}
+}
+
+func (m *stateManager) renderAnimated() {
+	a := m.animation
+
+	// Draw the state on a transparent texture
+	m.target.pushRenderTarget()
+	m.renderer.SetDrawColor(0, 0, 0, 0)
+	m.renderer.Clear()
+	a.state.render()
+	popRenderTarget()
+
+	p := a.progress()
+	var x, y int32
+	m.target.setAlpha(255)
+	switch a.trans.kind {
+	case TRANSITION_FADE:
+		m.target.setAlpha(uint8(p * 255))
+	case TRANSITION_SLIDE_LEFT:
+		x = int32((1 - p) * float64(m.width))
+	case TRANSITION_SLIDE_DOWN:
+		y = -int32((1 - p) * float64(m.height))
+	}
+	m.target.render(x, y, nil)
+}
+
+func (m *stateManager) free() {
+	m.target.free()
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"github.com/veandco/go-sdl2/sdl_ttf"
+	"math"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const TEXT_FONT_SIZE = 16
+
+const WINDOW_TITLE = "SDL Tutorial"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+var gColorsTexture *MyTexture
+var gFadeTexture *MyTexture
+var gArrowTexture *MyTexture
+var gFont *ttf.Font
+
+// The curves raced against each other, one dot each
+var gEasings = []struct {
+	name string
+	ease easingFunc
+}{
+	{"linear", easeLinear},
+	{"in out quad", easeInOutQuad},
+	{"in out cubic", easeInOutCubic},
+	{"out elastic", easeOutElastic},
+	{"out bounce", easeOutBounce},
+	{"in out back", easeInOutBack},
+}
+
+var gLabels []*MyTexture
+
+var gTweens tweenManager
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+// A texture placed by its center, turned and scaled
+type sprite struct {
+	texture *MyTexture
+
+	x, y  float64
+	angle float64
+	scale float64
+}
+
+func (s *sprite) render() {
+	w := float64(s.texture.width) * s.scale
+	h := float64(s.texture.height) * s.scale
+	s.texture.renderScaledRotation(&sdl.Rect{int32(math.Round(s.x - w/2)), int32(math.Round(s.y - h/2)),
+		int32(math.Round(w)), int32(math.Round(h))}, s.angle)
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func NewTextMyTexture(renderer *sdl.Renderer, text string, font *ttf.Font, color sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromRenderedText(text, color, font)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) loadFromRenderedText(textureText string, textureColor sdl.Color, font *ttf.Font) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := font.RenderUTF8_Solid(textureText, textureColor)
+	must(err)
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+// Render stretched to dst, turned around its center
+func (t *MyTexture) renderScaledRotation(dst *sdl.Rect, angle float64) {
+	gRenderer.CopyEx(t.texture, nil, dst, angle, nil, sdl.FLIP_NONE)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow(WINDOW_TITLE, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init font system
+	err = ttf.Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+	gColorsTexture = NewMyTexture(gRenderer, "assets/colors.png", nil)
+	gArrowTexture = NewMyTexture(gRenderer, "assets/arrow.png", nil)
+
+	// Alpha needs blending, as in lesson 13
+	gFadeTexture = NewMyTexture(gRenderer, "assets/fadein.png", nil)
+	gFadeTexture.setBlendMode(sdl.BLENDMODE_BLEND)
+
+	var err error
+	gFont, err = ttf.OpenFont("assets/lazy.ttf", TEXT_FONT_SIZE)
+	must(err)
+
+	for _, e := range gEasings {
+		gLabels = append(gLabels, NewTextMyTexture(gRenderer, e.name, gFont, sdl.Color{0, 0, 0, 255}))
+	}
+}
+
+func close() {
+	gDotTexture.free()
+	gColorsTexture.free()
+	gFadeTexture.free()
+	gArrowTexture.free()
+	for _, label := range gLabels {
+		label.free()
+	}
+	gFont.Close()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	ttf.Quit()
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Starts every animation of the lesson from the beginning
+func startTweens(dots []sprite, colors, fade, arrow *sprite) {
+	gTweens.clear()
+
+	// The dots race along their rows and back, each with its own curve
+	for i := range dots {
+		y := dots[i].y
+		gTweens.add(NewPositionTween(&dots[i], point{160, y}, point{600, y}, 1.5, gEasings[i].ease).
+			setDelay(0.5).setYoyo(true).setRepeat(-1))
+	}
+
+	// Tints through the primaries, one after the other
+	white := sdl.Color{255, 255, 255, 255}
+	red := sdl.Color{255, 64, 64, 255}
+	green := sdl.Color{64, 255, 64, 255}
+	blue := sdl.Color{64, 64, 255, 255}
+	gTweens.add(NewSequence(
+		NewColorTween(colors.texture, white, red, 0.8, easeInOutQuad),
+		NewColorTween(colors.texture, red, green, 0.8, easeInOutQuad),
+		NewColorTween(colors.texture, green, blue, 0.8, easeInOutQuad),
+		NewColorTween(colors.texture, blue, white, 0.8, easeInOutQuad),
+	).setRepeat(-1))
+
+	// Fades in and out, instead of by 32 on every key press
+	gTweens.add(NewAlphaTween(fade.texture, 0, 255, 1.2, easeInOutCubic).setYoyo(true).setRepeat(-1))
+
+	// A turn while growing and shrinking back, then a rest
+	gTweens.add(NewSequence(
+		NewGroup(
+			NewRotationTween(arrow, 0, 360, 1.5, easeInOutBack),
+			NewScaleTween(arrow, 0.4, 0.6, 0.75, easeOutQuad).setYoyo(true).setRepeat(1),
+		),
+		NewDelay(0.5),
+	).setRepeat(-1))
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	// One dot per curve, in rows
+	dots := make([]sprite, len(gEasings))
+	for i := range dots {
+		dots[i] = sprite{texture: gDotTexture, x: 160, y: float64(30 + i*36), scale: 1}
+	}
+
+	// The full screen images of lessons 12, 13 and 15, shrunk
+	colors := &sprite{texture: gColorsTexture, x: 110, y: 360, scale: 0.3}
+	fade := &sprite{texture: gFadeTexture, x: 320, y: 360, scale: 0.3}
+	arrow := &sprite{texture: gArrowTexture, x: 530, y: 360, scale: 0.4}
+
+	startTweens(dots, colors, fade, arrow)
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var paused bool
+	gWindow.SetTitle(WINDOW_TITLE + " - Space: restart, P: pause")
+
+	last := sdl.GetTicks()
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				if t.Repeat != 0 {
+					break
+				}
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_SPACE:
+					startTweens(dots, colors, fade, arrow)
+				case sdl.SCANCODE_P:
+					paused = !paused
+				}
+			}
+		}
+
+		// Tweens run on time, not frames
+		now := sdl.GetTicks()
+		if !paused {
+			gTweens.update(float64(now-last) / 1000)
+		}
+		last = now
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// The rows with their start and end marks
+		gRenderer.SetDrawColor(200, 200, 200, 255)
+		for i := range dots {
+			y := int32(dots[i].y)
+			gRenderer.DrawLine(160, int(y), 600, int(y))
+			gLabels[i].render(10, y-gLabels[i].height/2, nil)
+		}
+		for i := range dots {
+			dots[i].render()
+		}
+
+		colors.render()
+		fade.render()
+		arrow.render()
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+)
+
+/* ------------------------------ easing ------------------------------ */
+
+// Maps the linear progress of a tween, from 0 to 1, to the progress of the
+// value. Back and elastic curves overshoot below 0 and above 1.
+type easingFunc func(t float64) float64
+
+func easeLinear(t float64) float64 {
+	return t
+}
+
+func easeInQuad(t float64) float64 {
+	return t * t
+}
+
+func easeOutQuad(t float64) float64 {
+	return 1 - easeInQuad(1-t)
+}
+
+func easeInOutQuad(t float64) float64 {
+	return inOut(easeInQuad, t)
+}
+
+func easeInCubic(t float64) float64 {
+	return t * t * t
+}
+
+func easeOutCubic(t float64) float64 {
+	return 1 - easeInCubic(1-t)
+}
+
+func easeInOutCubic(t float64) float64 {
+	return inOut(easeInCubic, t)
+}
+
+// Pulls back a little before going
+func easeInBack(t float64) float64 {
+	const overshoot = 1.70158
+	return t * t * ((overshoot+1)*t - overshoot)
+}
+
+func easeOutBack(t float64) float64 {
+	return 1 - easeInBack(1-t)
+}
+
+func easeInOutBack(t float64) float64 {
+	return inOut(easeInBack, t)
+}
+
+// Springs past the end and wobbles into place
+func easeOutElastic(t float64) float64 {
+	if t == 0 || t == 1 {
+		return t
+	}
+	return math.Pow(2, -10*t)*math.Sin((t*10-0.75)*2*math.Pi/3) + 1
+}
+
+func easeInElastic(t float64) float64 {
+	return 1 - easeOutElastic(1-t)
+}
+
+func easeInOutElastic(t float64) float64 {
+	return inOut(easeInElastic, t)
+}
+
+// Falls and bounces on the end, each bounce lower
+func easeOutBounce(t float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+func easeInBounce(t float64) float64 {
+	return 1 - easeOutBounce(1-t)
+}
+
+func easeInOutBounce(t float64) float64 {
+	return inOut(easeInBounce, t)
+}
+
+// The in curve for the first half, mirrored for the second
+func inOut(in easingFunc, t float64) float64 {
+	if t < 0.5 {
+		return in(t*2) / 2
+	}
+	return 1 - in((1-t)*2)/2
+}
+
+/* ------------------------------ animators ------------------------------ */
+
+// Anything advanced by delta time: tweens, sequences, groups and delays
+type animator interface {
+	// Moves time forward by dt seconds and returns the part of dt left over
+	// once the animator is done, which a sequence hands to the next one
+	advance(dt float64) float64
+
+	isDone() bool
+
+	// Back to the start, for playing again
+	reset()
+}
+
+// Animates a value of type T from one value to another, handing every new
+// value to apply
+type tween[T any] struct {
+	from, to T
+
+	// In seconds
+	duration float64
+	delay    float64
+
+	ease  easingFunc
+	lerp  func(a, b T, t float64) T
+	apply func(value T)
+
+	// Extra plays after the first, -1 for ever. With yoyo every other play
+	// runs backwards.
+	repeat int
+	yoyo   bool
+
+	elapsed float64
+	done    bool
+}
+
+func NewTween[T any](from, to T, duration float64, ease easingFunc, lerp func(a, b T, t float64) T, apply func(value T)) *tween[T] {
+	if ease == nil {
+		ease = easeLinear
+	}
+	return &tween[T]{from: from, to: to, duration: duration, ease: ease, lerp: lerp, apply: apply}
+}
+
+// Waits before the first play only
+func (t *tween[T]) setDelay(seconds float64) *tween[T] {
+	t.delay = seconds
+	return t
+}
+
+func (t *tween[T]) setRepeat(count int) *tween[T] {
+	t.repeat = count
+	return t
+}
+
+func (t *tween[T]) setYoyo(yoyo bool) *tween[T] {
+	t.yoyo = yoyo
+	return t
+}
+
+func (t *tween[T]) advance(dt float64) float64 {
+	if t.done {
+		return dt
+	}
+
+	t.elapsed += dt
+	time := t.elapsed - t.delay
+	if time < 0 {
+		return 0
+	}
+
+	if t.duration <= 0 {
+		t.finish()
+		return time
+	}
+
+	play := int(time / t.duration)
+	if t.repeat >= 0 && play > t.repeat {
+		t.finish()
+		return time - float64(t.repeat+1)*t.duration
+	}
+
+	p := (time - float64(play)*t.duration) / t.duration
+	if t.yoyo && play%2 == 1 {
+		p = 1 - p
+	}
+	t.apply(t.lerp(t.from, t.to, t.ease(p)))
+	return 0
+}
+
+// Lands exactly on the end value; a yoyo with an even number of plays ends
+// where it started
+func (t *tween[T]) finish() {
+	t.done = true
+	if t.yoyo && t.repeat%2 == 1 {
+		t.apply(t.from)
+	} else {
+		t.apply(t.to)
+	}
+}
+
+func (t *tween[T]) isDone() bool {
+	return t.done
+}
+
+func (t *tween[T]) reset() {
+	t.elapsed = 0
+	t.done = false
+}
+
+// Does nothing for a while; a pause between the steps of a sequence
+type delay struct {
+	duration float64
+	elapsed  float64
+}
+
+func NewDelay(seconds float64) *delay {
+	return &delay{duration: seconds}
+}
+
+func (d *delay) advance(dt float64) float64 {
+	d.elapsed += dt
+	if d.elapsed < d.duration {
+		return 0
+	}
+	left := d.elapsed - d.duration
+	d.elapsed = d.duration
+	return left
+}
+
+func (d *delay) isDone() bool {
+	return d.elapsed >= d.duration
+}
+
+func (d *delay) reset() {
+	d.elapsed = 0
+}
+
+// Plays its steps one after the other, then all of them again repeat more
+// times
+type sequence struct {
+	steps   []animator
+	current int
+
+	// Extra plays after the first, -1 for ever
+	repeat int
+	plays  int
+}
+
+func NewSequence(steps ...animator) *sequence {
+	return &sequence{steps: steps}
+}
+
+func (s *sequence) setRepeat(count int) *sequence {
+	s.repeat = count
+	return s
+}
+
+func (s *sequence) advance(dt float64) float64 {
+	playStart := dt
+	for !s.isDone() {
+		// Time left over by a step goes to the next one
+		if s.current < len(s.steps) {
+			dt = s.steps[s.current].advance(dt)
+			if !s.steps[s.current].isDone() {
+				return 0
+			}
+			s.current++
+			continue
+		}
+
+		// Played through; start over unless that was the last play
+		s.plays++
+		if s.repeat >= 0 && s.plays > s.repeat {
+			break
+		}
+		s.restart()
+
+		// A play taking no time would loop for ever
+		if dt <= 0 || dt == playStart {
+			return 0
+		}
+		playStart = dt
+	}
+	return dt
+}
+
+func (s *sequence) isDone() bool {
+	return s.current >= len(s.steps) && s.repeat >= 0 && s.plays > s.repeat
+}
+
+func (s *sequence) reset() {
+	s.restart()
+	s.plays = 0
+}
+
+func (s *sequence) restart() {
+	for _, step := range s.steps {
+		step.reset()
+	}
+	s.current = 0
+}
+
+// Plays its members side by side, and is done when the longest is
+type group struct {
+	members []animator
+
+	// Extra plays after the first, -1 for ever
+	repeat int
+	plays  int
+}
+
+func NewGroup(members ...animator) *group {
+	return &group{members: members}
+}
+
+func (g *group) setRepeat(count int) *group {
+	g.repeat = count
+	return g
+}
+
+func (g *group) advance(dt float64) float64 {
+	for !g.isDone() {
+		// The member finishing last leaves the least time over
+		left := dt
+		done := true
+		for _, m := range g.members {
+			if m.isDone() {
+				continue
+			}
+			l := m.advance(dt)
+			if m.isDone() {
+				left = math.Min(left, l)
+			} else {
+				done = false
+			}
+		}
+		if !done {
+			return 0
+		}
+
+		// Played through; start over unless that was the last play
+		g.plays++
+		if g.repeat >= 0 && g.plays > g.repeat {
+			return left
+		}
+		for _, m := range g.members {
+			m.reset()
+		}
+
+		// A play taking no time would loop for ever
+		if left <= 0 || left == dt {
+			return 0
+		}
+		dt = left
+	}
+	return dt
+}
+
+func (g *group) isDone() bool {
+	return g.repeat >= 0 && g.plays > g.repeat
+}
+
+func (g *group) reset() {
+	for _, m := range g.members {
+		m.reset()
+	}
+	g.plays = 0
+}
+
+/* ------------------------------ tween manager ------------------------------ */
+
+// Runs animators until they are done
+type tweenManager struct {
+	running []animator
+}
+
+func (m *tweenManager) add(a animator) animator {
+	m.running = append(m.running, a)
+	return a
+}
+
+// Advances everything by dt seconds and drops what finished
+func (m *tweenManager) update(dt float64) {
+	running := m.running[:0]
+	for _, a := range m.running {
+		a.advance(dt)
+		if !a.isDone() {
+			running = append(running, a)
+		}
+	}
+	m.running = running
+}
+
+func (m *tweenManager) clear() {
+	m.running = nil
+}
+
+/* ------------------------------ targets ------------------------------ */
+
+func lerpFloat(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// Overshooting curves are clamped to what a channel holds
+func lerpChannel(a, b uint8, t float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(lerpFloat(float64(a), float64(b), t)))))
+}
+
+func lerpColor(a, b sdl.Color, t float64) sdl.Color {
+	return sdl.Color{lerpChannel(a.R, b.R, t), lerpChannel(a.G, b.G, t), lerpChannel(a.B, b.B, t), lerpChannel(a.A, b.A, t)}
+}
+
+// A position between pixels, so slow tweens move smoothly
+type point struct {
+	x, y float64
+}
+
+func lerpPoint(a, b point, t float64) point {
+	return point{lerpFloat(a.x, b.x, t), lerpFloat(a.y, b.y, t)}
+}
+
+// Fades the texture; it needs a blending mode for the alpha to show. Every
+// sprite drawing the texture fades with it.
+func NewAlphaTween(texture *MyTexture, from, to uint8, duration float64, ease easingFunc) *tween[uint8] {
+	return NewTween(from, to, duration, ease, lerpChannel, texture.setAlpha)
+}
+
+// Tints the texture through its color mod
+func NewColorTween(texture *MyTexture, from, to sdl.Color, duration float64, ease easingFunc) *tween[sdl.Color] {
+	return NewTween(from, to, duration, ease, lerpColor, func(c sdl.Color) {
+		texture.setColor(c.R, c.G, c.B)
+	})
+}
+
+func NewPositionTween(s *sprite, from, to point, duration float64, ease easingFunc) *tween[point] {
+	return NewTween(from, to, duration, ease, lerpPoint, func(p point) {
+		s.x, s.y = p.x, p.y
+	})
+}
+
+// In degrees, clockwise
+func NewRotationTween(s *sprite, from, to float64, duration float64, ease easingFunc) *tween[float64] {
+	return NewTween(from, to, duration, ease, lerpFloat, func(angle float64) {
+		s.angle = angle
+	})
+}
+
+func NewScaleTween(s *sprite, from, to float64, duration float64, ease easingFunc) *tween[float64] {
+	return NewTween(from, to, duration, ease, lerpFloat, func(scale float64) {
+		s.scale = scale
+	})
+}
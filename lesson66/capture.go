@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+/* ------------------------------ screenshots ------------------------------ */
+
+// Packed pixel format laid out R, G, B, A in memory, like image.RGBA. SDL
+// names packed formats from the most significant byte down, so it depends on
+// the byte order of the machine.
+func rgbaMemoryFormat() uint32 {
+	one := uint16(1)
+	if *(*byte)(unsafe.Pointer(&one)) == 1 {
+		return sdl.PIXELFORMAT_ABGR8888
+	}
+	return sdl.PIXELFORMAT_RGBA8888
+}
+
+// Reads back what was rendered so far this frame. Call it before Present;
+// afterwards the contents of the back buffer are undefined.
+func readPixels(renderer *sdl.Renderer) (*image.RGBA, error) {
+	w, h, err := renderer.GetOutputSize()
+	if err != nil {
+		return nil, err
+	}
+
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("renderer output is %dx%d", w, h)
+	}
+
+	frame := image.NewRGBA(image.Rect(0, 0, w, h))
+	err = renderer.ReadPixels(nil, rgbaMemoryFormat(), unsafe.Pointer(&frame.Pix[0]), frame.Stride)
+	if err != nil {
+		return nil, err
+	}
+
+	// The window has no meaningful alpha; make the capture opaque
+	for i := 3; i < len(frame.Pix); i += 4 {
+		frame.Pix[i] = 255
+	}
+	return frame, nil
+}
+
+// Local time down to the millisecond, so captures sort by name and don't
+// collide
+func timestamp() string {
+	return time.Now().Format("20060102-150405.000")
+}
+
+func savePNG(frame image.Image, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(file, frame); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Saves the frame rendered so far as dir/screenshot-<time>.png and returns
+// the file name
+func saveScreenshot(renderer *sdl.Renderer, dir string) (string, error) {
+	frame, err := readPixels(renderer)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "screenshot-"+timestamp()+".png")
+	return path, savePNG(frame, path)
+}
+
+/* ------------------------------ recording ------------------------------ */
+
+const (
+	// Numbered PNG files in a directory
+	RECORD_PNG_SEQUENCE = iota
+
+	// One animated GIF, kept in memory until the recording stops
+	RECORD_GIF
+)
+
+// Captures the rendered frames at a fixed rate. The frames are read on the
+// main thread, as SDL requires, and encoded by a goroutine so the game keeps
+// its frame rate.
+type recorder struct {
+	renderer *sdl.Renderer
+	path     string
+	format   int
+	fps      int
+
+	// When the recording started, in the game's milliseconds
+	started  bool
+	start    uint32
+	captured int
+
+	// Frames on their way to the encoder; nil asks it to finish
+	frames chan *image.RGBA
+	done   sync.WaitGroup
+
+	// Set by the encoder, read once it's done
+	err error
+	gif gif.GIF
+}
+
+// Records into path: a GIF if it ends in .gif, a directory of PNG files
+// otherwise
+func NewRecorder(renderer *sdl.Renderer, path string, fps int) (*recorder, error) {
+	if fps <= 0 {
+		return nil, fmt.Errorf("recording at %d fps", fps)
+	}
+
+	r := &recorder{
+		renderer: renderer,
+		path:     path,
+		fps:      fps,
+		frames:   make(chan *image.RGBA, 8),
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".gif") {
+		r.format = RECORD_GIF
+	} else {
+		r.format = RECORD_PNG_SEQUENCE
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	r.done.Add(1)
+	go r.encode()
+	return r, nil
+}
+
+// Takes a frame if one is due at the given time, which should come from the
+// same clock the game animates with. A game running slower than the
+// recording repeats frames, so the recording keeps the game's pace.
+func (r *recorder) capture(now uint32) error {
+	if !r.started {
+		r.started = true
+		r.start = now
+	}
+	if now < r.frameTime(r.captured) {
+		return nil
+	}
+
+	frame, err := readPixels(r.renderer)
+	if err != nil {
+		return err
+	}
+	for r.frameTime(r.captured) <= now {
+		r.frames <- frame
+		r.captured++
+	}
+	return nil
+}
+
+// When frame n is due; rounding every frame on its own would drift
+func (r *recorder) frameTime(n int) uint32 {
+	return r.start + uint32(n*1000/r.fps)
+}
+
+// Waits for the encoder and writes the GIF, if that's what was recorded
+func (r *recorder) stop() error {
+	r.frames <- nil
+	r.done.Wait()
+	if r.err != nil || r.format != RECORD_GIF {
+		return r.err
+	}
+
+	file, err := os.Create(r.path)
+	if err != nil {
+		return err
+	}
+	if err = gif.EncodeAll(file, &r.gif); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Runs on its own goroutine. After the first error frames are only drained.
+func (r *recorder) encode() {
+	defer r.done.Done()
+
+	for n := 0; ; n++ {
+		frame := <-r.frames
+		if frame == nil {
+			return
+		}
+		if r.err != nil {
+			continue
+		}
+
+		switch r.format {
+		case RECORD_PNG_SEQUENCE:
+			r.err = savePNG(frame, filepath.Join(r.path, fmt.Sprintf("frame-%05d.png", n)))
+		case RECORD_GIF:
+			r.addGIFFrame(frame, n)
+		}
+	}
+}
+
+// GIF frames are limited to 256 colors and delays in hundredths of a second.
+// The delays alternate so the total matches the frame rate.
+func (r *recorder) addGIFFrame(frame *image.RGBA, n int) {
+	paletted := image.NewPaletted(frame.Bounds(), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, frame.Bounds(), frame, image.Point{})
+
+	delay := (n+1)*100/r.fps - n*100/r.fps
+	r.gif.Image = append(r.gif.Image, paletted)
+	r.gif.Delay = append(r.gif.Delay, delay)
+}
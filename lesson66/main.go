@@ -0,0 +1,363 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"os"
+	"path/filepath"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 10
+
+// The game clock moves this much every frame, however long the frame took,
+// so recordings made by CI look the same every time
+const FRAME_MS = 16
+
+const WINDOW_TITLE = "SDL Tutorial"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+var gArrowTexture *MyTexture
+
+// The recording running, if any
+var gRecorder *recorder
+
+// Use the software renderer, e.g. to run headless with SDL_VIDEODRIVER=dummy
+var gSoftware = flag.Bool("software", false, "use the software renderer")
+
+// Quit after this many frames; 0 runs until the window is closed
+var gFrames = flag.Int("frames", 0, "number of frames to render before quitting")
+
+var gCaptureDir = flag.String("dir", ".", "directory for screenshots and recordings")
+var gScreenshot = flag.Bool("screenshot", false, "save a screenshot of the last of -frames")
+var gRecordPath = flag.String("record", "", "record from the first frame into a .gif file or a directory of PNGs")
+var gRecordFormat = flag.String("format", "gif", "what F10 records: gif or png")
+var gFPS = flag.Int("fps", 20, "frames per second of recordings")
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y       int32
+	velX, velY int32
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX -= DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX += DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+func (d *dot) move() {
+	// Move the dot left or right
+	d.x += d.velX
+
+	// If the dot went too far to the left or right
+	if d.x < 0 || d.x+DOT_WIDTH > SCREEN_WIDTH {
+		d.x -= d.velX
+	}
+
+	// Move the dot up or down
+	d.y += d.velY
+
+	// If the dot went too far to the up or down
+	if d.y < 0 || d.y+DOT_HEIGHT > SCREEN_HEIGHT {
+		d.y -= d.velY
+	}
+}
+
+func (d *dot) render() {
+	gDotTexture.render(d.x, d.y, nil)
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+func (t *MyTexture) renderRotationFlip(x, y int32, clip *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.CopyEx(t.texture, clip, renderQuad, angle, center, flip)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow(WINDOW_TITLE, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer; the software one reads back pixels just the same
+	var flags uint32 = sdl.RENDERER_ACCELERATED | sdl.RENDERER_PRESENTVSYNC
+	if *gSoftware {
+		flags = sdl.RENDERER_SOFTWARE
+	}
+	renderer, err := sdl.CreateRenderer(window, -1, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+	gArrowTexture = NewMyTexture(gRenderer, "assets/arrow.png", nil)
+}
+
+func close() {
+	gDotTexture.free()
+	gArrowTexture.free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Starts or stops recording into the capture directory
+func toggleRecording() {
+	if gRecorder != nil {
+		stopRecording()
+		return
+	}
+
+	path := filepath.Join(*gCaptureDir, "recording-"+timestamp())
+	if *gRecordFormat == "gif" {
+		path += ".gif"
+	}
+
+	var err error
+	gRecorder, err = NewRecorder(gRenderer, path, *gFPS)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "recording:", err)
+		return
+	}
+	gWindow.SetTitle(WINDOW_TITLE + " - recording (F10 to stop)")
+}
+
+func stopRecording() {
+	if err := gRecorder.stop(); err != nil {
+		fmt.Fprintln(os.Stderr, "recording:", err)
+	} else {
+		fmt.Println("recorded", gRecorder.captured, "frames to", gRecorder.path)
+	}
+	gRecorder = nil
+	gWindow.SetTitle(WINDOW_TITLE + " - F12: screenshot, F10: record")
+}
+
+func takeScreenshot() {
+	path, err := saveScreenshot(gRenderer, *gCaptureDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "screenshot:", err)
+		return
+	}
+	fmt.Println("saved", path)
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	flag.Parse()
+
+	if *gRecordFormat != "gif" && *gRecordFormat != "png" {
+		fmt.Fprintln(os.Stderr, "unknown recording format", *gRecordFormat)
+		os.Exit(2)
+	}
+
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	gWindow.SetTitle(WINDOW_TITLE + " - F12: screenshot, F10: record")
+	if *gRecordPath != "" {
+		gRecorder, err = NewRecorder(gRenderer, *gRecordPath, *gFPS)
+		must(err)
+	}
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var d dot
+
+	var quit bool
+	for frame := 0; !quit; frame++ {
+		if *gFrames > 0 && frame >= *gFrames {
+			break
+		}
+
+		screenshot := *gScreenshot && frame == *gFrames-1
+
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				if t.Repeat != 0 {
+					break
+				}
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_F12:
+					screenshot = true
+				case sdl.SCANCODE_F10:
+					toggleRecording()
+				}
+			}
+
+			d.handleEvent(event)
+		}
+
+		// Move the dot
+		d.move()
+
+		ticks := uint32(frame) * FRAME_MS
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// A turning arrow, so recordings have something to show
+		gArrowTexture.renderRotationFlip((SCREEN_WIDTH-gArrowTexture.width)/2, (SCREEN_HEIGHT-gArrowTexture.height)/2,
+			nil, float64(ticks)/1000*90, nil, sdl.FLIP_NONE)
+		d.render()
+
+		// Captures have to be read before Present
+		if screenshot {
+			takeScreenshot()
+		}
+		if gRecorder != nil {
+			must(gRecorder.capture(ticks))
+
+			// Drawn after the capture, so it isn't recorded
+			gRenderer.SetDrawColor(255, 0, 0, 255)
+			gRenderer.FillRect(&sdl.Rect{SCREEN_WIDTH - 24, 8, 16, 16})
+		}
+
+		// Update screen
+		gRenderer.Present()
+
+		sdl.Delay(16)
+	}
+
+	if gRecorder != nil {
+		stopRecording()
+	}
+
+	close()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"cmp"
+	"github.com/veandco/go-sdl2/sdl"
+	"slices"
+)
+
+/* ------------------------------ sprite batch ------------------------------ */
+
+// A textured quad waiting to be drawn
+type batchQuad struct {
+	texture *MyTexture
+	blend   sdl.BlendMode
+	layer   int
+
+	src   sdl.Rect
+	dst   sdl.Rect
+	color sdl.Color
+
+	// Submission order, which ties keep
+	index int
+}
+
+// Collects quads during a frame and draws them at flush, grouped by texture
+// and blend mode. Every quad is still a Copy, but the texture, blend mode and
+// tint only change between groups, which is what breaks up the renderer's
+// own batching, and quads come out of buffers kept from frame to frame
+// instead of allocated per draw.
+//
+// Grouping reorders quads of different textures. Quads meant to overlap in a
+// given order go on different layers, which are drawn in order.
+type spriteBatch struct {
+	renderer *sdl.Renderer
+
+	// Reused from flush to flush
+	quads []batchQuad
+	src   sdl.Rect
+	dst   sdl.Rect
+
+	// Where a texture is first used in the frame; textures sort by it
+	rank map[*MyTexture]int
+
+	// Layer of the quads drawn from now on
+	layer int
+
+	// Texture or blend mode changes made by the last flush
+	switches int
+}
+
+func NewSpriteBatch(renderer *sdl.Renderer) *spriteBatch {
+	return &spriteBatch{
+		renderer: renderer,
+		rank:     make(map[*MyTexture]int),
+	}
+}
+
+func (b *spriteBatch) setLayer(layer int) {
+	b.layer = layer
+}
+
+// Queues the texture at x, y, untinted; clip nil is the whole texture
+func (b *spriteBatch) draw(texture *MyTexture, clip *sdl.Rect, x, y int32) {
+	src := sdl.Rect{0, 0, texture.width, texture.height}
+	if clip != nil {
+		src = *clip
+	}
+	b.drawEx(texture, src, sdl.Rect{x, y, src.W, src.H}, sdl.Color{255, 255, 255, 255})
+}
+
+// Queues part of the texture stretched to dst and tinted with color, whose
+// alpha applies when the texture blends
+func (b *spriteBatch) drawEx(texture *MyTexture, src sdl.Rect, dst sdl.Rect, color sdl.Color) {
+	if _, ok := b.rank[texture]; !ok {
+		b.rank[texture] = len(b.rank)
+	}
+	b.quads = append(b.quads, batchQuad{texture, texture.blendMode, b.layer, src, dst, color, len(b.quads)})
+}
+
+// Draws everything queued, by layer, texture and blend mode, and empties
+// the batch
+func (b *spriteBatch) flush() {
+	// sort.Slice would allocate on every flush
+	slices.SortFunc(b.quads, func(qi, qj batchQuad) int {
+		if qi.layer != qj.layer {
+			return cmp.Compare(qi.layer, qj.layer)
+		}
+		if ri, rj := b.rank[qi.texture], b.rank[qj.texture]; ri != rj {
+			return cmp.Compare(ri, rj)
+		}
+		if qi.blend != qj.blend {
+			return cmp.Compare(qi.blend, qj.blend)
+		}
+		return cmp.Compare(qi.index, qj.index)
+	})
+
+	b.switches = 0
+	for start := 0; start < len(b.quads); {
+		// Quads sharing state, drawn together
+		end := start + 1
+		for end < len(b.quads) && b.quads[end].texture == b.quads[start].texture &&
+			b.quads[end].blend == b.quads[start].blend && b.quads[end].layer == b.quads[start].layer {
+			end++
+		}
+
+		b.quads[start].texture.texture.SetBlendMode(b.quads[start].blend)
+		b.drawCopies(b.quads[start:end])
+		b.switches++
+		start = end
+	}
+
+	b.quads = b.quads[:0]
+	clear(b.rank)
+}
+
+// One Copy per quad, changing the tint only when it changes
+func (b *spriteBatch) drawCopies(quads []batchQuad) {
+	texture := quads[0].texture.texture
+	tint := quads[0].color
+	texture.SetColorMod(tint.R, tint.G, tint.B)
+	texture.SetAlphaMod(tint.A)
+
+	for i := range quads {
+		q := &quads[i]
+		if q.color != tint {
+			tint = q.color
+			texture.SetColorMod(tint.R, tint.G, tint.B)
+			texture.SetAlphaMod(tint.A)
+		}
+		b.src, b.dst = q.src, q.dst
+		b.renderer.Copy(texture, &b.src, &b.dst)
+	}
+
+	// Leave the texture untinted for anyone drawing it directly
+	texture.SetColorMod(255, 255, 255)
+	texture.SetAlphaMod(255)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"testing"
+)
+
+// Draws on a software renderer into a surface, so the benchmarks run
+// headless; go test -bench . compares the drawing modes
+func setupRenderer(b *testing.B) {
+	b.Helper()
+
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, SCREEN_WIDTH, SCREEN_HEIGHT, 32, sdl.PIXELFORMAT_RGBA8888)
+	if err != nil {
+		b.Fatal(err)
+	}
+	gRenderer, err = sdl.CreateSoftwareRenderer(surface)
+	if err != nil {
+		surface.Free()
+		b.Fatal(err)
+	}
+
+	loadMedia()
+	gBatch = NewSpriteBatch(gRenderer)
+
+	b.Cleanup(func() {
+		gDotTexture.free()
+		gGlowTexture.free()
+		gRenderer.Destroy()
+		surface.Free()
+	})
+}
+
+func benchmarkDots(b *testing.B, mode int) {
+	for _, count := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("%d dots", count), func(b *testing.B) {
+			setupRenderer(b)
+			dots := newDots(count)
+
+			// Let the batch grow its buffers before measuring
+			renderDots(dots, mode)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			switches := 0
+			for i := 0; i < b.N; i++ {
+				for j := range dots {
+					dots[j].move()
+				}
+				switches = renderDots(dots, mode)
+			}
+			b.ReportMetric(float64(switches), "switches/frame")
+		})
+	}
+}
+
+// One Copy per dot, straight from MyTexture.render
+func BenchmarkNaive(b *testing.B) {
+	benchmarkDots(b, MODE_NAIVE)
+}
+
+func BenchmarkBatch(b *testing.B) {
+	benchmarkDots(b, MODE_BATCH)
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"math/rand"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dots
+const DOT_VEL = 4
+
+// Ways of drawing the dots, compared by the benchmarks
+const (
+	// MyTexture.render for every dot
+	MODE_NAIVE = iota
+
+	// The sprite batch, grouping dots by texture
+	MODE_BATCH
+
+	MODE_TOTAL
+)
+
+var gModeNames = [MODE_TOTAL]string{"naive", "batch"}
+
+const WINDOW_TITLE = "SDL Tutorial"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+// The same dot, plain and glowing with additive blending. The dots alternate
+// between them, the worst order for drawing one by one.
+var gDotTexture *MyTexture
+var gGlowTexture *MyTexture
+
+var gBatch *spriteBatch
+
+var gDotCount = flag.Int("dots", 10000, "number of dots")
+
+// Use the software renderer, e.g. to run headless with SDL_VIDEODRIVER=dummy
+var gSoftware = flag.Bool("software", false, "use the software renderer")
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+// The dot of lesson 26, bouncing by itself
+type dot struct {
+	x, y       int32
+	velX, velY int32
+
+	texture *MyTexture
+}
+
+func (d *dot) move() {
+	// Move the dot left or right
+	d.x += d.velX
+
+	// If the dot went too far to the left or right, bounce
+	if d.x < 0 || d.x+DOT_WIDTH > SCREEN_WIDTH {
+		d.x -= d.velX
+		d.velX = -d.velX
+	}
+
+	// Move the dot up or down
+	d.y += d.velY
+
+	// If the dot went too far to the up or down, bounce
+	if d.y < 0 || d.y+DOT_HEIGHT > SCREEN_HEIGHT {
+		d.y -= d.velY
+		d.velY = -d.velY
+	}
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+
+	// Kept for the sprite batch, which groups by it
+	blendMode sdl.BlendMode
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Surfaces with alpha or a color key give blended textures
+	t.blendMode, err = t.texture.GetBlendMode()
+	must(err)
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+	t.blendMode = bm
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow(WINDOW_TITLE, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer; vsync would hide the difference between the modes
+	var flags uint32 = sdl.RENDERER_ACCELERATED
+	if *gSoftware {
+		flags = sdl.RENDERER_SOFTWARE
+	}
+	renderer, err := sdl.CreateRenderer(window, -1, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+	gGlowTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+	gGlowTexture.setBlendMode(sdl.BLENDMODE_ADD)
+}
+
+func close() {
+	gDotTexture.free()
+	gGlowTexture.free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Dots all over the screen, always from the same seed so every run and every
+// mode draws the same thing
+func newDots(count int) []dot {
+	random := rand.New(rand.NewSource(1))
+	dots := make([]dot, count)
+	for i := range dots {
+		dots[i] = dot{
+			x:       random.Int31n(SCREEN_WIDTH - DOT_WIDTH),
+			y:       random.Int31n(SCREEN_HEIGHT - DOT_HEIGHT),
+			velX:    random.Int31n(2*DOT_VEL+1) - DOT_VEL,
+			velY:    random.Int31n(2*DOT_VEL+1) - DOT_VEL,
+			texture: gDotTexture,
+		}
+		if i%2 == 1 {
+			dots[i].texture = gGlowTexture
+		}
+	}
+	return dots
+}
+
+// Draws one frame of dots and returns the number of texture switches made
+func renderDots(dots []dot, mode int) int {
+	// Clear screen
+	gRenderer.SetDrawColor(0, 0, 0, 255)
+	gRenderer.Clear()
+
+	switches := 0
+	switch mode {
+	case MODE_NAIVE:
+		// The textures alternate, so every dot switches
+		for i := range dots {
+			dots[i].texture.render(dots[i].x, dots[i].y, nil)
+		}
+		switches = len(dots)
+	case MODE_BATCH:
+		for i := range dots {
+			gBatch.draw(dots[i].texture, nil, dots[i].x, dots[i].y)
+		}
+		gBatch.flush()
+		switches = gBatch.switches
+	}
+
+	// Update screen
+	gRenderer.Present()
+	return switches
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	flag.Parse()
+
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	gBatch = NewSpriteBatch(gRenderer)
+
+	dots := newDots(*gDotCount)
+	mode := MODE_BATCH
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	// Frames drawn since the title was last updated
+	var frames int
+	lastTitle := sdl.GetTicks()
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				// Switch between the ways of drawing
+				if t.Keysym.Scancode == sdl.SCANCODE_M && t.Repeat == 0 {
+					mode = (mode + 1) % MODE_TOTAL
+				}
+			}
+		}
+
+		for i := range dots {
+			dots[i].move()
+		}
+
+		// No delay; the frame rate shows what drawing costs
+		switches := renderDots(dots, mode)
+
+		// Frame rate once a second
+		frames++
+		if now := sdl.GetTicks(); now-lastTitle >= 1000 {
+			fps := float64(frames) * 1000 / float64(now-lastTitle)
+			gWindow.SetTitle(fmt.Sprintf("%s - %s: %.0f fps, %d texture switches (M to switch)", WINDOW_TITLE, gModeNames[mode], fps, switches))
+			frames = 0
+			lastTitle = now
+		}
+	}
+
+	close()
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"math"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+// Per frame, while the keys are held. Fractions of a pixel add up in the
+// sprite's position, but it is drawn at whole pixels.
+const (
+	ARROW_VEL    = 1.5
+	ARROW_TURN   = 2
+	ARROW_GROWTH = 1.02
+)
+
+// Small arrows circling the screen, partly off it
+const (
+	ORBIT_COUNT  = 16
+	ORBIT_RADIUS = 280
+)
+
+const WINDOW_TITLE = "SDL Tutorial - pixel-snapped sprites"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gArrowTexture *MyTexture
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setAlpha(alpha uint8) {
+	t.texture.SetAlphaMod(alpha)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+// Like renderRotationFlip of lesson 15, but stretched to fill dst; center is
+// relative to dst
+func (t *MyTexture) renderScaledRotationFlip(clip *sdl.Rect, dst *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) {
+	gRenderer.CopyEx(t.texture, clip, dst, angle, center, flip)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow(WINDOW_TITLE, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gArrowTexture = NewMyTexture(gRenderer, "assets/arrow.png", nil)
+	gArrowTexture.setBlendMode(sdl.BLENDMODE_BLEND)
+}
+
+func close() {
+	gArrowTexture.free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Outlines the rotated sprite and the box around it
+func renderBounds(s *sprite) {
+	corners := s.corners()
+	var points [5]sdl.Point
+	for i := range points {
+		c := corners[i%4]
+		points[i] = sdl.Point{int32(math.Round(c.x)), int32(math.Round(c.y))}
+	}
+	gRenderer.SetDrawColor(0, 160, 0, 255)
+	gRenderer.DrawLines(points[:])
+
+	b := s.bounds()
+	gRenderer.SetDrawColor(0, 0, 255, 255)
+	gRenderer.DrawRect(&sdl.Rect{int32(math.Floor(b.x)), int32(math.Floor(b.y)), int32(math.Ceil(b.w)), int32(math.Ceil(b.h))})
+
+	// The pivot
+	gRenderer.SetDrawColor(255, 0, 0, 255)
+	gRenderer.FillRect(&sdl.Rect{int32(s.x) - 2, int32(s.y) - 2, 5, 5})
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	// The arrow of lesson 15, in the middle
+	arrow := NewSprite(gArrowTexture, nil)
+	arrow.setPosition(SCREEN_WIDTH/2, SCREEN_HEIGHT/2)
+	arrow.setScale(0.5, 0.5)
+
+	// Fainter the further around the circle
+	orbit := make([]*sprite, ORBIT_COUNT)
+	for i := range orbit {
+		orbit[i] = NewSprite(gArrowTexture, nil)
+		orbit[i].setScale(0.15, 0.15)
+		orbit[i].alpha = uint8(255 - i*255/(2*ORBIT_COUNT))
+		orbit[i].tint = sdl.Color{255, uint8(i * 255 / ORBIT_COUNT), 0, 255}
+	}
+
+	view := rect{0, 0, SCREEN_WIDTH, SCREEN_HEIGHT}
+	showBounds := true
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_Q:
+					arrow.flip = sdl.FLIP_HORIZONTAL
+				case sdl.SCANCODE_W:
+					arrow.flip = sdl.FLIP_NONE
+				case sdl.SCANCODE_E:
+					arrow.flip = sdl.FLIP_VERTICAL
+				case sdl.SCANCODE_1:
+					arrow.setPivot(0, 0)
+				case sdl.SCANCODE_2:
+					arrow.setPivot(0.5, 0.5)
+				case sdl.SCANCODE_3:
+					arrow.setPivot(1, 1)
+				case sdl.SCANCODE_B:
+					showBounds = !showBounds
+				}
+			}
+		}
+
+		// Held keys move, turn and scale the arrow a little every frame
+		keys := sdl.GetKeyboardState()
+		if keys[sdl.SCANCODE_LEFT] != 0 {
+			arrow.x -= ARROW_VEL
+		}
+		if keys[sdl.SCANCODE_RIGHT] != 0 {
+			arrow.x += ARROW_VEL
+		}
+		if keys[sdl.SCANCODE_UP] != 0 {
+			arrow.y -= ARROW_VEL
+		}
+		if keys[sdl.SCANCODE_DOWN] != 0 {
+			arrow.y += ARROW_VEL
+		}
+		if keys[sdl.SCANCODE_A] != 0 {
+			arrow.angle -= ARROW_TURN
+		}
+		if keys[sdl.SCANCODE_D] != 0 {
+			arrow.angle += ARROW_TURN
+		}
+		if keys[sdl.SCANCODE_EQUALS] != 0 {
+			arrow.setScale(arrow.scaleX*ARROW_GROWTH, arrow.scaleY*ARROW_GROWTH)
+		}
+		if keys[sdl.SCANCODE_MINUS] != 0 {
+			arrow.setScale(arrow.scaleX/ARROW_GROWTH, arrow.scaleY/ARROW_GROWTH)
+		}
+
+		// Red while the mouse is over the arrow itself, not just its box
+		mouseX, mouseY, _ := sdl.GetMouseState()
+		if arrow.contains(float64(mouseX), float64(mouseY)) {
+			arrow.tint = sdl.Color{255, 96, 96, 255}
+		} else {
+			arrow.tint = sdl.Color{255, 255, 255, 255}
+		}
+
+		// The small arrows turn around the center, pointing the way they go
+		seconds := float64(sdl.GetTicks()) / 1000
+		for i, s := range orbit {
+			a := seconds*0.5 + float64(i)*2*math.Pi/ORBIT_COUNT
+			s.setPosition(SCREEN_WIDTH/2+ORBIT_RADIUS*math.Cos(a), SCREEN_HEIGHT/2+ORBIT_RADIUS*math.Sin(a))
+			s.angle = a*180/math.Pi + 90
+		}
+
+		// Clear screen
+		gRenderer.SetDrawColor(255, 255, 255, 255)
+		gRenderer.Clear()
+
+		// Only what overlaps the screen is drawn
+		drawn := 0
+		for _, s := range orbit {
+			if s.overlaps(view) {
+				s.render()
+				drawn++
+			}
+		}
+
+		arrow.render()
+		if showBounds {
+			renderBounds(arrow)
+		}
+
+		// Update screen
+		gRenderer.Present()
+
+		gWindow.SetTitle(fmt.Sprintf("%s - %d of %d small arrows drawn (arrows, A/D, +/-, Q/W/E, 1-3, B)",
+			WINDOW_TITLE, drawn, ORBIT_COUNT))
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
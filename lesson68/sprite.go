@@ -0,0 +1,166 @@
+package main
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+)
+
+/* ------------------------------ sprite ------------------------------ */
+
+type point struct {
+	x, y float64
+}
+
+type rect struct {
+	x, y, w, h float64
+}
+
+// A texture, or part of one, placed in float coordinates. Position, rotation,
+// scaling and flipping all happen around the pivot.
+type sprite struct {
+	texture *MyTexture
+
+	// Part of the texture to draw, the whole texture if nil
+	clip *sdl.Rect
+
+	// Where the pivot is drawn
+	x, y float64
+
+	// A negative scale mirrors the sprite, like flip
+	scaleX, scaleY float64
+
+	// In degrees, clockwise
+	angle float64
+
+	// Normalized: 0, 0 is the top left corner of the sprite, 1, 1 the bottom
+	// right one
+	pivotX, pivotY float64
+
+	flip sdl.RendererFlip
+
+	// Color and alpha modulation, applied when drawing, so sprites sharing a
+	// texture can look different
+	tint  sdl.Color
+	alpha uint8
+}
+
+// A sprite at the origin, unscaled, pivoting around its center
+func NewSprite(texture *MyTexture, clip *sdl.Rect) *sprite {
+	return &sprite{
+		texture: texture,
+		clip:    clip,
+		scaleX:  1,
+		scaleY:  1,
+		pivotX:  0.5,
+		pivotY:  0.5,
+		tint:    sdl.Color{255, 255, 255, 255},
+		alpha:   255,
+	}
+}
+
+func (s *sprite) setPosition(x, y float64) {
+	s.x, s.y = x, y
+}
+
+func (s *sprite) setScale(scaleX, scaleY float64) {
+	s.scaleX, s.scaleY = scaleX, scaleY
+}
+
+func (s *sprite) setPivot(pivotX, pivotY float64) {
+	s.pivotX, s.pivotY = pivotX, pivotY
+}
+
+// Unscaled size of the part of the texture drawn
+func (s *sprite) clipSize() (float64, float64) {
+	if s.clip != nil {
+		return float64(s.clip.W), float64(s.clip.H)
+	}
+	return float64(s.texture.width), float64(s.texture.height)
+}
+
+// Size on screen, before rotation, the flip including negative scales, and
+// where the pivot ends up from the top left corner of that area. Flipping
+// mirrors around the pivot, so a sprite doesn't jump when it turns around.
+func (s *sprite) layout() (w, h, pivotX, pivotY float64, flip sdl.RendererFlip) {
+	w, h = s.clipSize()
+	flip = s.flip
+	if s.scaleX < 0 {
+		flip ^= sdl.FLIP_HORIZONTAL
+	}
+	if s.scaleY < 0 {
+		flip ^= sdl.FLIP_VERTICAL
+	}
+	w *= math.Abs(s.scaleX)
+	h *= math.Abs(s.scaleY)
+
+	pivotX, pivotY = s.pivotX*w, s.pivotY*h
+	if flip&sdl.FLIP_HORIZONTAL != 0 {
+		pivotX = w - pivotX
+	}
+	if flip&sdl.FLIP_VERTICAL != 0 {
+		pivotY = h - pivotY
+	}
+	return
+}
+
+// The corners of the rotated sprite on screen, clockwise from the top left
+// one of the texture
+func (s *sprite) corners() [4]point {
+	w, h, pivotX, pivotY, _ := s.layout()
+	sin, cos := math.Sincos(s.angle * math.Pi / 180)
+
+	var corners [4]point
+	for i, c := range [4][2]float64{{0, 0}, {w, 0}, {w, h}, {0, h}} {
+		x, y := c[0]-pivotX, c[1]-pivotY
+		corners[i] = point{s.x + x*cos - y*sin, s.y + x*sin + y*cos}
+	}
+	return corners
+}
+
+// The smallest axis-aligned box around the rotated sprite, for culling
+func (s *sprite) bounds() rect {
+	corners := s.corners()
+	minX, minY := corners[0].x, corners[0].y
+	maxX, maxY := minX, minY
+	for _, c := range corners[1:] {
+		minX, maxX = min(minX, c.x), max(maxX, c.x)
+		minY, maxY = min(minY, c.y), max(maxY, c.y)
+	}
+	return rect{minX, minY, maxX - minX, maxY - minY}
+}
+
+// Whether any of the sprite may show in the view
+func (s *sprite) overlaps(view rect) bool {
+	b := s.bounds()
+	return b.x < view.x+view.w && view.x < b.x+b.w && b.y < view.y+view.h && view.y < b.y+b.h
+}
+
+// Whether the point is on the rotated sprite, for picking it with the mouse.
+// The point is turned back into the sprite's unrotated frame, where the test
+// is against a plain rectangle.
+func (s *sprite) contains(x, y float64) bool {
+	w, h, pivotX, pivotY, _ := s.layout()
+	sin, cos := math.Sincos(-s.angle * math.Pi / 180)
+	x, y = x-s.x, y-s.y
+	localX := x*cos - y*sin + pivotX
+	localY := x*sin + y*cos + pivotY
+	return localX >= 0 && localX < w && localY >= 0 && localY < h
+}
+
+// The go-sdl2 this repo builds against predates RenderCopyExF, so there is
+// no float CopyEx: the sprite is drawn at whole pixels, with its size,
+// position and pivot rounded. Only the state, bounds and hit-testing above
+// are in floats; moving and scaling step a pixel at a time on screen.
+func (s *sprite) render() {
+	w, h, pivotX, pivotY, flip := s.layout()
+
+	// Rounded so that the pivot lands on the rounded position, which keeps
+	// turning and flipping sprites from wobbling
+	center := &sdl.Point{int32(math.Round(pivotX)), int32(math.Round(pivotY))}
+	dst := &sdl.Rect{int32(math.Round(s.x)) - center.X, int32(math.Round(s.y)) - center.Y,
+		int32(math.Round(w)), int32(math.Round(h))}
+
+	s.texture.setColor(s.tint.R, s.tint.G, s.tint.B)
+	s.texture.setAlpha(s.alpha)
+	s.texture.renderScaledRotationFlip(s.clip, dst, s.angle, center, flip)
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/* ------------------------------ color spaces ------------------------------ */
+
+// Hue in degrees, saturation and value from 0 to 1
+type hsv struct {
+	h, s, v float64
+	a       uint8
+}
+
+// Hue in degrees, saturation and lightness from 0 to 1
+type hsl struct {
+	h, s, l float64
+	a       uint8
+}
+
+func toHSV(c sdl.Color) hsv {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	maxC, minC := max(r, g, b), min(r, g, b)
+
+	s := 0.0
+	if maxC > 0 {
+		s = (maxC - minC) / maxC
+	}
+	return hsv{hue(r, g, b, maxC, minC), s, maxC, c.A}
+}
+
+func toHSL(c sdl.Color) hsl {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	maxC, minC := max(r, g, b), min(r, g, b)
+
+	l := (maxC + minC) / 2
+	s := 0.0
+	if maxC > minC {
+		s = (maxC - minC) / (1 - math.Abs(2*l-1))
+	}
+	return hsl{hue(r, g, b, maxC, minC), s, l, c.A}
+}
+
+// Hue shared by HSV and HSL, 0 for grays
+func hue(r, g, b, maxC, minC float64) float64 {
+	chroma := maxC - minC
+	var h float64
+	switch {
+	case chroma == 0:
+		return 0
+	case maxC == r:
+		h = math.Mod((g-b)/chroma, 6)
+	case maxC == g:
+		h = (b-r)/chroma + 2
+	default:
+		h = (r-g)/chroma + 4
+	}
+	if h < 0 {
+		h += 6
+	}
+	return h * 60
+}
+
+func (c hsv) rgba() sdl.Color {
+	chroma := clamp01(c.v) * clamp01(c.s)
+	return fromChroma(c.h, chroma, clamp01(c.v)-chroma, c.a)
+}
+
+func (c hsl) rgba() sdl.Color {
+	l := clamp01(c.l)
+	chroma := (1 - math.Abs(2*l-1)) * clamp01(c.s)
+	return fromChroma(c.h, chroma, l-chroma/2, c.a)
+}
+
+// The color of the given hue and chroma, lightened by m
+func fromChroma(h, chroma, m float64, a uint8) sdl.Color {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	h /= 60
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g, b = chroma, x, 0
+	case 1:
+		r, g, b = x, chroma, 0
+	case 2:
+		r, g, b = 0, chroma, x
+	case 3:
+		r, g, b = 0, x, chroma
+	case 4:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return sdl.Color{toByte(r + m), toByte(g + m), toByte(b + m), a}
+}
+
+func clamp01(v float64) float64 {
+	return min(max(v, 0), 1)
+}
+
+func toByte(v float64) uint8 {
+	return uint8(math.Round(clamp01(v) * 255))
+}
+
+// sRGB channels are gamma encoded; light adds up in linear ones
+func toLinear(channel uint8) float64 {
+	c := float64(channel) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func fromLinear(c float64) uint8 {
+	c = clamp01(c)
+	if c <= 0.0031308 {
+		return toByte(c * 12.92)
+	}
+	return toByte(1.055*math.Pow(c, 1/2.4) - 0.055)
+}
+
+// Oklab, where equal steps look about equally different; see
+// https://bottosson.github.io/posts/oklab/
+func toOklab(c sdl.Color) (float64, float64, float64) {
+	r, g, b := toLinear(c.R), toLinear(c.G), toLinear(c.B)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+}
+
+func fromOklab(lightness, a, b float64, alpha uint8) sdl.Color {
+	l := lightness + 0.3963377774*a + 0.2158037573*b
+	m := lightness - 0.1055613458*a - 0.0638541728*b
+	s := lightness - 0.0894841775*a - 1.2914855480*b
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return sdl.Color{
+		fromLinear(4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		fromLinear(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		fromLinear(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s),
+		alpha,
+	}
+}
+
+/* ------------------------------ interpolation ------------------------------ */
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+func lerpByte(a, b uint8, t float64) uint8 {
+	return uint8(math.Round(lerp(float64(a), float64(b), t)))
+}
+
+// Straight through the gamma-encoded channels, as SDL blends; halfway
+// between two bright colors comes out too dark
+func lerpRGB(a, b sdl.Color, t float64) sdl.Color {
+	return sdl.Color{lerpByte(a.R, b.R, t), lerpByte(a.G, b.G, t), lerpByte(a.B, b.B, t), lerpByte(a.A, b.A, t)}
+}
+
+// Through linear light, as a mix of the two lights would look
+func lerpLinear(a, b sdl.Color, t float64) sdl.Color {
+	return sdl.Color{
+		fromLinear(lerp(toLinear(a.R), toLinear(b.R), t)),
+		fromLinear(lerp(toLinear(a.G), toLinear(b.G), t)),
+		fromLinear(lerp(toLinear(a.B), toLinear(b.B), t)),
+		lerpByte(a.A, b.A, t),
+	}
+}
+
+// Through Oklab, in perceptually even steps
+func lerpOklab(a, b sdl.Color, t float64) sdl.Color {
+	l1, a1, b1 := toOklab(a)
+	l2, a2, b2 := toOklab(b)
+	return fromOklab(lerp(l1, l2, t), lerp(a1, a2, t), lerp(b1, b2, t), lerpByte(a.A, b.A, t))
+}
+
+// Around the hue circle the short way, keeping colors saturated
+func lerpHSV(a, b sdl.Color, t float64) sdl.Color {
+	ha, hb := toHSV(a), toHSV(b)
+
+	// Grays have no hue; take the other's
+	if ha.s == 0 {
+		ha.h = hb.h
+	}
+	if hb.s == 0 {
+		hb.h = ha.h
+	}
+
+	dh := math.Mod(hb.h-ha.h+540, 360) - 180
+	return hsv{ha.h + dh*t, lerp(ha.s, hb.s, t), lerp(ha.v, hb.v, t), lerpByte(a.A, b.A, t)}.rgba()
+}
+
+/* ------------------------------ parsing ------------------------------ */
+
+// Colors known by name, besides hex
+var gColorNames = map[string]sdl.Color{
+	"black":   {0, 0, 0, 255},
+	"white":   {255, 255, 255, 255},
+	"red":     {255, 0, 0, 255},
+	"green":   {0, 255, 0, 255},
+	"blue":    {0, 0, 255, 255},
+	"cyan":    {0, 255, 255, 255},
+	"magenta": {255, 0, 255, 255},
+	"yellow":  {255, 255, 0, 255},
+}
+
+// Parses #rgb, #rgba, #rrggbb and #rrggbbaa, with or without the #
+func parseHex(s string) (sdl.Color, error) {
+	digits := strings.TrimPrefix(s, "#")
+	if len(digits) == 3 || len(digits) == 4 {
+		// Every digit doubled: #0f8 is #00ff88
+		var long strings.Builder
+		for _, d := range digits {
+			long.WriteRune(d)
+			long.WriteRune(d)
+		}
+		digits = long.String()
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	if len(digits) != 8 {
+		return sdl.Color{}, fmt.Errorf("invalid hex color %q", s)
+	}
+
+	v, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return sdl.Color{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	return sdl.Color{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// Formats as #rrggbb, or #rrggbbaa if not opaque
+func toHex(c sdl.Color) string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// Any of the ways a color can be given: sdl.Color or a pointer to one, hsv,
+// hsl, or a string with a color name or hex digits
+func toColor(v any) (sdl.Color, error) {
+	switch c := v.(type) {
+	case sdl.Color:
+		return c, nil
+	case *sdl.Color:
+		if c == nil {
+			return sdl.Color{}, fmt.Errorf("nil color")
+		}
+		return *c, nil
+	case hsv:
+		return c.rgba(), nil
+	case hsl:
+		return c.rgba(), nil
+	case string:
+		if named, ok := gColorNames[strings.ToLower(c)]; ok {
+			return named, nil
+		}
+		return parseHex(c)
+	}
+	return sdl.Color{}, fmt.Errorf("not a color: %v", v)
+}
+
+/* ------------------------------ palettes ------------------------------ */
+
+type palette []sdl.Color
+
+// A palette from colors in any form toColor takes
+func NewPalette(colors ...any) palette {
+	p := make(palette, len(colors))
+	for i, c := range colors {
+		var err error
+		p[i], err = toColor(c)
+		must(err)
+	}
+	return p
+}
+
+// Palettes known by name
+var gPalettes = map[string]palette{
+	"pico8": NewPalette(
+		"#000000", "#1d2b53", "#7e2553", "#008751", "#ab5236", "#5f574f", "#c2c3c7", "#fff1e8",
+		"#ff004d", "#ffa300", "#ffec27", "#00e436", "#29adff", "#83769c", "#ff77a8", "#ffccaa"),
+	"grayscale": NewPalette("#000", "#444", "#888", "#ccc", "#fff"),
+}
+
+// The entry closest to c, by distance in Oklab
+func (p palette) nearest(c sdl.Color) int {
+	l, a, b := toOklab(c)
+	best, bestDistance := 0, math.Inf(1)
+	for i, entry := range p {
+		pl, pa, pb := toOklab(entry)
+		distance := (pl-l)*(pl-l) + (pa-a)*(pa-a) + (pb-b)*(pb-b)
+		if distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return best
+}
+
+/* ------------------------------ palette swap ------------------------------ */
+
+// Format of swapped surfaces, one uint32 per pixel
+const SWAP_FORMAT = sdl.PIXELFORMAT_ARGB8888
+
+// A copy of the surface in which every pixel of color from[i] is to[i], e.g. a
+// sprite sheet in team colors. Colors match exactly, alpha aside, which keeps
+// its own value; pixel art has few colors, smoothed images too many for this.
+func swapPalette(surface *sdl.Surface, from, to palette) (*sdl.Surface, error) {
+	if len(from) != len(to) {
+		return nil, fmt.Errorf("swapping %d colors for %d", len(from), len(to))
+	}
+
+	swapped, err := surface.ConvertFormat(SWAP_FORMAT, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	format := swapped.Format
+	colors := make(map[uint32]uint32, len(from))
+	for i := range from {
+		colors[sdl.MapRGB(format, from[i].R, from[i].G, from[i].B)&^format.Amask] =
+			sdl.MapRGB(format, to[i].R, to[i].G, to[i].B) &^ format.Amask
+	}
+
+	if swapped.MustLock() {
+		if err := swapped.Lock(); err != nil {
+			swapped.Free()
+			return nil, err
+		}
+		defer swapped.Unlock()
+	}
+
+	// Row by row, the pitch may include padding
+	pixels := swapped.Pixels()
+	for y := 0; y < int(swapped.H); y++ {
+		row := pixels[y*int(swapped.Pitch):]
+		for x := 0; x < int(swapped.W); x++ {
+			pixel := binary.NativeEndian.Uint32(row[x*4:])
+			if c, ok := colors[pixel&^format.Amask]; ok {
+				binary.NativeEndian.PutUint32(row[x*4:], c|pixel&format.Amask)
+			}
+		}
+	}
+	return swapped, nil
+}
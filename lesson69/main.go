@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+// Team colored figures across the top
+const (
+	FIGURE_X       = 16
+	FIGURE_Y       = 16
+	FIGURE_SPACING = 80
+)
+
+// One gradient per way of interpolating, under the figures
+const (
+	GRADIENT_X       = 16
+	GRADIENT_Y       = 176
+	GRADIENT_WIDTH   = 608
+	GRADIENT_HEIGHT  = 40
+	GRADIENT_SPACING = 48
+)
+
+const (
+	SWATCH_Y      = 392
+	SWATCH_HEIGHT = 40
+)
+
+// Degrees the tinted figure's hue turns every second
+const HUE_SPEED = 90
+
+const WINDOW_TITLE = "SDL Tutorial"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+// The black figure of lesson 10 in every team's color. Its background is the
+// same cyan every time, given a different way each time.
+var gTeamTextures []*MyTexture
+
+// The figure in white, tinted with setColor
+var gTintTexture *MyTexture
+
+// Palette the gradient ends are picked from
+var gSwatches = gPalettes["pico8"]
+
+var gGradients = []func(a, b sdl.Color, t float64) sdl.Color{lerpRGB, lerpLinear, lerpOklab, lerpHSV}
+var gGradientNames = []string{"sRGB", "linear", "Oklab", "HSV"}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+// colorKey is anything toColor takes, or nil for none; a nil *sdl.Color
+// counts as nil too
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey any) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+// Loads the image with the colors of from swapped for those of to
+func NewSwappedMyTexture(renderer *sdl.Renderer, path string, colorKey any, from, to palette) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadSwappedFromFile(path, colorKey, from, to)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey any) {
+	t.loadSwappedFromFile(path, colorKey, nil, nil)
+}
+
+func (t *MyTexture) loadSwappedFromFile(path string, colorKey any, from, to palette) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if from != nil {
+		swapped, err := swapPalette(surface, from, to)
+		must(err)
+		surface.Free()
+		surface = swapped
+	}
+
+	// A nil *sdl.Color in colorKey is not a nil any
+	if c, ok := colorKey.(*sdl.Color); ok && c == nil {
+		colorKey = nil
+	}
+	if colorKey != nil {
+		key, err := toColor(colorKey)
+		must(err)
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, key.R, key.G, key.B))
+	}
+
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+
+	// Free loaded surface
+	surface.Free()
+}
+
+func (t *MyTexture) setColor(c sdl.Color) {
+	t.texture.SetColorMod(c.R, c.G, c.B)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow(WINDOW_TITLE, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	// The figure is all black
+	figure := NewPalette("black")
+
+	gTeamTextures = []*MyTexture{
+		NewSwappedMyTexture(gRenderer, "assets/foo.png", sdl.Color{0, 255, 255, 255}, figure, NewPalette("#d03030")),
+		NewSwappedMyTexture(gRenderer, "assets/foo.png", "#0ff", figure, NewPalette(hsv{220, 0.8, 0.9, 255})),
+		NewSwappedMyTexture(gRenderer, "assets/foo.png", "cyan", figure, NewPalette(hsl{130, 0.6, 0.4, 255})),
+		NewSwappedMyTexture(gRenderer, "assets/foo.png", hsv{180, 1, 1, 255}, figure, palette{gSwatches[9]}),
+	}
+	gTintTexture = NewSwappedMyTexture(gRenderer, "assets/foo.png", &sdl.Color{0, 255, 255, 255}, figure, NewPalette("white"))
+}
+
+func close() {
+	for _, t := range gTeamTextures {
+		t.free()
+	}
+	gTintTexture.free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func fillRect(c sdl.Color, rect *sdl.Rect) {
+	gRenderer.SetDrawColor(c.R, c.G, c.B, c.A)
+	gRenderer.FillRect(rect)
+}
+
+// A column of color for every pixel from a to b
+func renderGradient(y int32, a, b sdl.Color, gradient func(a, b sdl.Color, t float64) sdl.Color) {
+	column := sdl.Rect{0, y, 1, GRADIENT_HEIGHT}
+	for x := int32(0); x < GRADIENT_WIDTH; x++ {
+		column.X = GRADIENT_X + x
+		fillRect(gradient(a, b, float64(x)/(GRADIENT_WIDTH-1)), &column)
+	}
+}
+
+// The palette with the gradient ends outlined
+func renderSwatches(from, to int) {
+	width := int32(GRADIENT_WIDTH / len(gSwatches))
+	for i, c := range gSwatches {
+		rect := sdl.Rect{GRADIENT_X + int32(i)*width, SWATCH_Y, width, SWATCH_HEIGHT}
+		fillRect(c, &rect)
+
+		if i == from || i == to {
+			gRenderer.SetDrawColor(255, 255, 255, 255)
+			gRenderer.DrawRect(&sdl.Rect{rect.X - 2, rect.Y - 2, rect.W + 4, rect.H + 4})
+		}
+	}
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	loadMedia()
+
+	// Gradient ends, in gSwatches
+	from, to := 8, 12
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var quit bool
+	for !quit {
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				// Pick the gradient ends
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_UP:
+					from = (from + 1) % len(gSwatches)
+				case sdl.SCANCODE_DOWN:
+					from = (from + len(gSwatches) - 1) % len(gSwatches)
+				case sdl.SCANCODE_RIGHT:
+					to = (to + 1) % len(gSwatches)
+				case sdl.SCANCODE_LEFT:
+					to = (to + len(gSwatches) - 1) % len(gSwatches)
+				}
+			}
+		}
+
+		// The tint goes around the hue circle, as lesson 12's keys would
+		tint := hsv{float64(sdl.GetTicks()) / 1000 * HUE_SPEED, 1, 1, 255}.rgba()
+		gTintTexture.setColor(tint)
+
+		// Clear screen
+		gRenderer.SetDrawColor(32, 32, 32, 255)
+		gRenderer.Clear()
+
+		for i, t := range gTeamTextures {
+			t.render(FIGURE_X+int32(i)*FIGURE_SPACING, FIGURE_Y, nil)
+		}
+		tintX := int32(FIGURE_X + len(gTeamTextures)*FIGURE_SPACING)
+		gTintTexture.render(tintX, FIGURE_Y, nil)
+
+		// The tint, and the closest the palette has to it
+		fillRect(tint, &sdl.Rect{tintX + FIGURE_SPACING, FIGURE_Y + 32, 64, 64})
+		fillRect(gSwatches[gSwatches.nearest(tint)], &sdl.Rect{tintX + 2*FIGURE_SPACING, FIGURE_Y + 32, 64, 64})
+
+		for i, gradient := range gGradients {
+			renderGradient(GRADIENT_Y+int32(i)*GRADIENT_SPACING, gSwatches[from], gSwatches[to], gradient)
+		}
+		renderSwatches(from, to)
+
+		// Update screen
+		gRenderer.Present()
+
+		gWindow.SetTitle(fmt.Sprintf("%s - %s to %s in %v (up/down, left/right)",
+			WINDOW_TITLE, toHex(gSwatches[from]), toHex(gSwatches[to]), gGradientNames))
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/binary"
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+)
+
+/* ------------------------------ lights ------------------------------ */
+
+const (
+	// Shines all around
+	LIGHT_POINT = iota
+
+	// Shines one way, like a flashlight
+	LIGHT_CONE
+)
+
+// Size of the generated light textures; lights are drawn stretched to their
+// radius, and falloff is smooth enough not to show it
+const LIGHT_TEXTURE_SIZE = 256
+
+// Half the angle cones shine over, in degrees
+const CONE_SPREAD = 30
+
+type light struct {
+	kind int
+
+	// Center of a point light, tip of a cone
+	x, y float64
+
+	// Distance at which the light fades out
+	radius float64
+
+	color sdl.Color
+
+	// Direction of a cone, in degrees clockwise from the right
+	angle float64
+
+	// How much the light dims at its most flickery, 0 for a steady light
+	flicker float64
+
+	// Keeps lights with the same flicker out of step
+	phase float64
+
+	on bool
+
+	// Brightness from 0 to 1, flicker included, set by update
+	intensity float64
+}
+
+func NewPointLight(x, y, radius float64, color sdl.Color) *light {
+	return &light{kind: LIGHT_POINT, x: x, y: y, radius: radius, color: color, on: true, intensity: 1}
+}
+
+func NewConeLight(x, y, radius float64, color sdl.Color, angle float64) *light {
+	return &light{kind: LIGHT_CONE, x: x, y: y, radius: radius, color: color, angle: angle, on: true, intensity: 1}
+}
+
+// Makes the light flicker by up to amount
+func (l *light) setFlicker(amount, phase float64) {
+	l.flicker, l.phase = amount, phase
+}
+
+// A few sines out of step make a flame that doesn't look like it repeats
+func (l *light) update(ticks uint32) {
+	t := float64(ticks)/1000 + l.phase
+	noise := 0.5 + 0.25*math.Sin(t*7.3) + 0.15*math.Sin(t*13.7+1.3) + 0.1*math.Sin(t*29.1+2.9)
+	l.intensity = 1 - l.flicker*noise
+}
+
+/* ------------------------------ light layer ------------------------------ */
+
+// Darkness with lights in it, laid over the scene. Every frame the light map
+// is cleared to the ambient color and the lights are added into it; the map
+// then multiplies the scene, so black hides it, white leaves it as is and
+// colors tint it. All of it is plain blend modes and render targets, which
+// the software renderer has too.
+type lightLayer struct {
+	renderer *sdl.Renderer
+
+	// Render target the lights are added into
+	lightMap *MyTexture
+
+	// White light, fading out towards the edges, tinted with color mod
+	pointTexture *MyTexture
+	coneTexture  *MyTexture
+
+	// What unlit places look like; black is pitch dark
+	ambient sdl.Color
+
+	lights []*light
+}
+
+func NewLightLayer(renderer *sdl.Renderer, width, height int32, ambient sdl.Color) *lightLayer {
+	l := &lightLayer{renderer: renderer, ambient: ambient}
+
+	l.lightMap = NewTargetMyTexture(renderer, width, height)
+	l.lightMap.setBlendMode(sdl.BLENDMODE_MOD)
+
+	l.pointTexture = NewLightMyTexture(renderer, LIGHT_TEXTURE_SIZE, 180)
+	l.coneTexture = NewLightMyTexture(renderer, LIGHT_TEXTURE_SIZE, CONE_SPREAD)
+	return l
+}
+
+func (l *lightLayer) free() {
+	l.lightMap.free()
+	l.pointTexture.free()
+	l.coneTexture.free()
+}
+
+func (l *lightLayer) add(lt *light) *light {
+	l.lights = append(l.lights, lt)
+	return lt
+}
+
+func (l *lightLayer) update(ticks uint32) {
+	for _, lt := range l.lights {
+		lt.update(ticks)
+	}
+}
+
+// Multiplies the light map into the current render target; call it once the
+// scene is drawn and before anything that should stay lit, like a HUD
+func (l *lightLayer) render() {
+	l.lightMap.pushRenderTarget()
+	l.renderer.SetDrawColor(l.ambient.R, l.ambient.G, l.ambient.B, 255)
+	l.renderer.Clear()
+
+	for _, lt := range l.lights {
+		if !lt.on {
+			continue
+		}
+
+		texture := l.pointTexture
+		if lt.kind == LIGHT_CONE {
+			texture = l.coneTexture
+		}
+
+		// Dimming the color dims the light; where lights overlap they add up
+		texture.setColor(scaleChannel(lt.color.R, lt.intensity), scaleChannel(lt.color.G, lt.intensity),
+			scaleChannel(lt.color.B, lt.intensity))
+
+		// Both textures are centered on the light, so cones turn around their tip
+		r := int32(lt.radius)
+		texture.renderScaledRotationFlip(nil, &sdl.Rect{int32(lt.x) - r, int32(lt.y) - r, 2 * r, 2 * r}, lt.angle, nil, sdl.FLIP_NONE)
+	}
+	popRenderTarget()
+
+	l.lightMap.render(0, 0, nil)
+}
+
+func scaleChannel(c uint8, intensity float64) uint8 {
+	return uint8(math.Round(float64(c) * min(max(intensity, 0), 1)))
+}
+
+// A square white light texture, shining from its center to its edges over
+// spread degrees either side of the right, all around for 180
+func NewLightMyTexture(renderer *sdl.Renderer, size int32, spread float64) *MyTexture {
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, size, size, 32, sdl.PIXELFORMAT_ARGB8888)
+	must(err)
+
+	if surface.MustLock() {
+		must(surface.Lock())
+	}
+
+	// Row by row, the pitch may include padding
+	pixels := surface.Pixels()
+	half := float64(size) / 2
+	for y := int32(0); y < size; y++ {
+		row := pixels[int(y)*int(surface.Pitch):]
+		for x := int32(0); x < size; x++ {
+			dx, dy := (float64(x)+0.5-half)/half, (float64(y)+0.5-half)/half
+
+			// Quadratic falloff looks softer than linear
+			brightness := max(1-math.Hypot(dx, dy), 0)
+			brightness *= brightness
+
+			// Cones fade out over the last few degrees of their edges
+			if spread < 180 {
+				off := math.Abs(math.Atan2(dy, dx)) * 180 / math.Pi
+				brightness *= min(max((spread-off)/5, 0), 1)
+			}
+
+			v := uint32(math.Round(brightness * 255))
+			binary.NativeEndian.PutUint32(row[x*4:], 0xff000000|v<<16|v<<8|v)
+		}
+	}
+
+	if surface.MustLock() {
+		surface.Unlock()
+	}
+
+	t := &MyTexture{renderer: renderer}
+	t.loadFromSurface(surface)
+	surface.Free()
+
+	// Lights add up
+	t.setBlendMode(sdl.BLENDMODE_ADD)
+	return t
+}
@@ -0,0 +1,460 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"math"
+	"unsafe"
+)
+
+/* ------------------------------ global constants ------------------------------ */
+
+const (
+	SCREEN_WIDTH  = 640
+	SCREEN_HEIGHT = 480
+)
+
+const (
+	DOT_WIDTH  = 20
+	DOT_HEIGHT = 20
+)
+
+// Maximum axis velocity of the dot
+const DOT_VEL = 4
+
+// Size of the floor tiles
+const TILE_SIZE = 40
+
+// The game clock moves this much every frame, however long the frame took,
+// so flicker looks the same in every headless run
+const FRAME_MS = 16
+
+// Change of the ambient light per key press
+const AMBIENT_STEP = 8
+
+const WINDOW_TITLE = "SDL Tutorial"
+
+/* ------------------------------ global variables ------------------------------ */
+
+var gWindow *sdl.Window
+var gRenderer *sdl.Renderer
+
+var gDotTexture *MyTexture
+
+var gLights *lightLayer
+
+// The dungeon's walls
+var gWalls = []sdl.Rect{
+	{0, 0, SCREEN_WIDTH, TILE_SIZE},
+	{0, SCREEN_HEIGHT - TILE_SIZE, SCREEN_WIDTH, TILE_SIZE},
+	{0, 0, TILE_SIZE, SCREEN_HEIGHT},
+	{SCREEN_WIDTH - TILE_SIZE, 0, TILE_SIZE, SCREEN_HEIGHT},
+	{5 * TILE_SIZE, 4 * TILE_SIZE, 6 * TILE_SIZE, TILE_SIZE},
+	{10 * TILE_SIZE, 5 * TILE_SIZE, TILE_SIZE, 3 * TILE_SIZE},
+}
+
+// Use the software renderer, e.g. to run headless with SDL_VIDEODRIVER=dummy
+var gSoftware = flag.Bool("software", false, "use the software renderer")
+
+// Quit after this many frames; 0 runs until the window is closed
+var gFrames = flag.Int("frames", 0, "number of frames to render before quitting")
+
+var gScreenshot = flag.String("screenshot", "", "save the last of -frames to this BMP file")
+
+/* ------------------------------ lesson-specific types ------------------------------ */
+
+type dot struct {
+	x, y       int32
+	velX, velY int32
+}
+
+func (d *dot) handleEvent(e sdl.Event) {
+	switch t := e.(type) {
+	case *sdl.KeyDownEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX -= DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX += DOT_VEL
+			}
+		}
+	case *sdl.KeyUpEvent:
+		if t.Repeat == 0 {
+			switch t.Keysym.Scancode {
+			case sdl.SCANCODE_UP:
+				d.velY += DOT_VEL
+			case sdl.SCANCODE_DOWN:
+				d.velY -= DOT_VEL
+			case sdl.SCANCODE_LEFT:
+				d.velX += DOT_VEL
+			case sdl.SCANCODE_RIGHT:
+				d.velX -= DOT_VEL
+			}
+		}
+	}
+}
+
+// Moves the dot, stopping at the walls
+func (d *dot) move() {
+	// Move the dot left or right
+	d.x += d.velX
+	if d.hitsWall() {
+		d.x -= d.velX
+	}
+
+	// Move the dot up or down
+	d.y += d.velY
+	if d.hitsWall() {
+		d.y -= d.velY
+	}
+}
+
+func (d *dot) hitsWall() bool {
+	box := sdl.Rect{d.x, d.y, DOT_WIDTH, DOT_HEIGHT}
+	for i := range gWalls {
+		if box.HasIntersection(&gWalls[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *dot) render() {
+	gDotTexture.render(d.x, d.y, nil)
+}
+
+/* ------------------------------ MyTexture ------------------------------ */
+
+type MyTexture struct {
+	// The renderer
+	renderer *sdl.Renderer
+
+	// The actual hardware texture
+	texture *sdl.Texture
+
+	// Image size
+	width  int32
+	height int32
+}
+
+func NewMyTexture(renderer *sdl.Renderer, path string, colorKey *sdl.Color) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.loadFromFile(path, colorKey)
+	return t
+}
+
+// Creates a texture that can be drawn into with pushRenderTarget
+func NewTargetMyTexture(renderer *sdl.Renderer, width, height int32) *MyTexture {
+	t := &MyTexture{renderer: renderer}
+	t.createBlank(width, height, sdl.TEXTUREACCESS_TARGET)
+
+	// Keep transparent parts transparent when drawn onto something else
+	t.setBlendMode(sdl.BLENDMODE_BLEND)
+	return t
+}
+
+func (t *MyTexture) free() {
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
+	}
+}
+
+func (t *MyTexture) loadFromFile(path string, colorKey *sdl.Color) {
+	// Free pre-existing texture
+	t.free()
+
+	surface, err := img.Load(path)
+	must(err)
+
+	if colorKey != nil {
+		surface.SetColorKey(1, sdl.MapRGB(surface.Format, colorKey.R, colorKey.G, colorKey.B))
+	}
+
+	t.loadFromSurface(surface)
+
+	// Free loaded surface
+	surface.Free()
+}
+
+// Uploads the surface, which stays the caller's to free
+func (t *MyTexture) loadFromSurface(surface *sdl.Surface) {
+	// Free pre-existing texture
+	t.free()
+
+	var err error
+	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	must(err)
+
+	t.width = surface.W
+	t.height = surface.H
+}
+
+func (t *MyTexture) createBlank(width, height int32, access int) {
+	// Free pre-existing texture
+	t.free()
+
+	var err error
+	t.texture, err = t.renderer.CreateTexture(sdl.PIXELFORMAT_RGBA8888, access, int(width), int(height))
+	must(err)
+
+	t.width = width
+	t.height = height
+}
+
+func (t *MyTexture) setColor(r, g, b uint8) {
+	t.texture.SetColorMod(r, g, b)
+}
+
+func (t *MyTexture) setBlendMode(bm sdl.BlendMode) {
+	t.texture.SetBlendMode(bm)
+}
+
+func (t *MyTexture) render(x, y int32, clip *sdl.Rect) {
+	renderQuad := &sdl.Rect{x, y, t.width, t.height}
+	if clip != nil {
+		renderQuad.W = clip.W
+		renderQuad.H = clip.H
+	}
+	gRenderer.Copy(t.texture, clip, renderQuad)
+}
+
+// Render stretched to fill dst, turned around center, the middle of dst if nil
+func (t *MyTexture) renderScaledRotationFlip(clip *sdl.Rect, dst *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) {
+	gRenderer.CopyEx(t.texture, clip, dst, angle, center, flip)
+}
+
+/* ------------------------------ render targets ------------------------------ */
+
+// Textures currently drawn into, innermost last. Every push must be
+// matched by a pop; the window is the target once the stack is empty.
+var gTargetStack []*MyTexture
+
+// Make the texture the target of all drawing until popRenderTarget
+func (t *MyTexture) pushRenderTarget() {
+	must(t.renderer.SetRenderTarget(t.texture))
+	gTargetStack = append(gTargetStack, t)
+}
+
+// Go back to drawing into the previous target
+func popRenderTarget() {
+	top := gTargetStack[len(gTargetStack)-1]
+	gTargetStack = gTargetStack[:len(gTargetStack)-1]
+
+	var previous *sdl.Texture
+	if len(gTargetStack) > 0 {
+		previous = gTargetStack[len(gTargetStack)-1].texture
+	}
+	must(top.renderer.SetRenderTarget(previous))
+}
+
+/* ------------------------------ other ------------------------------ */
+
+func initSDL() (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	// Create window
+	window, err := sdl.CreateWindow(WINDOW_TITLE, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create renderer; both kinds need to support render targets
+	var flags uint32 = sdl.RENDERER_ACCELERATED | sdl.RENDERER_PRESENTVSYNC | sdl.RENDERER_TARGETTEXTURE
+	if *gSoftware {
+		flags = sdl.RENDERER_SOFTWARE | sdl.RENDERER_TARGETTEXTURE
+	}
+	renderer, err := sdl.CreateRenderer(window, -1, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return window, renderer, nil
+}
+
+func loadMedia() {
+	gDotTexture = NewMyTexture(gRenderer, "assets/dot.bmp", nil)
+
+	gLights = NewLightLayer(gRenderer, SCREEN_WIDTH, SCREEN_HEIGHT, sdl.Color{})
+	setAmbient(24)
+}
+
+func close() {
+	gDotTexture.free()
+	gLights.free()
+
+	gRenderer.Destroy()
+	gWindow.Destroy()
+
+	// Quit SDL subsystems
+	img.Quit()
+	sdl.Quit()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Night blue darkness, pitch black at 0
+func setAmbient(level int) {
+	level = min(max(level, 0), 255)
+	gLights.ambient = sdl.Color{uint8(level), uint8(level), uint8(min(level*5/3, 255)), 255}
+}
+
+// Torches on the walls, flickering out of step, and a steady blue crystal
+func addLights() {
+	torches := []sdl.Point{{3 * TILE_SIZE, TILE_SIZE}, {12 * TILE_SIZE, TILE_SIZE}, {TILE_SIZE, 9 * TILE_SIZE}, {14 * TILE_SIZE, 11 * TILE_SIZE}}
+	for i, p := range torches {
+		torch := gLights.add(NewPointLight(float64(p.X), float64(p.Y), 160, sdl.Color{255, 160, 64, 255}))
+		torch.setFlicker(0.3, float64(i)*1.7)
+	}
+	gLights.add(NewPointLight(8*TILE_SIZE, 8*TILE_SIZE, 120, sdl.Color{64, 128, 255, 255}))
+}
+
+// Stone floor, with the walls on top; what is lit, not the light
+func renderScene() {
+	for y := int32(0); y < SCREEN_HEIGHT; y += TILE_SIZE {
+		for x := int32(0); x < SCREEN_WIDTH; x += TILE_SIZE {
+			if (x+y)/TILE_SIZE%2 == 0 {
+				gRenderer.SetDrawColor(150, 140, 130, 255)
+			} else {
+				gRenderer.SetDrawColor(130, 120, 112, 255)
+			}
+			gRenderer.FillRect(&sdl.Rect{x, y, TILE_SIZE, TILE_SIZE})
+		}
+	}
+
+	gRenderer.SetDrawColor(90, 80, 80, 255)
+	gRenderer.FillRects(gWalls)
+
+	// The lights themselves
+	for _, l := range gLights.lights {
+		if l.kind == LIGHT_POINT {
+			gRenderer.SetDrawColor(l.color.R, l.color.G, l.color.B, 255)
+			gRenderer.FillRect(&sdl.Rect{int32(l.x) - 4, int32(l.y) - 4, 8, 8})
+		}
+	}
+}
+
+// Saves what was rendered so far this frame; call it before Present
+func saveScreenshot(path string) error {
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, SCREEN_WIDTH, SCREEN_HEIGHT, 32, sdl.PIXELFORMAT_ARGB8888)
+	if err != nil {
+		return err
+	}
+	defer surface.Free()
+
+	err = gRenderer.ReadPixels(nil, sdl.PIXELFORMAT_ARGB8888, unsafe.Pointer(&surface.Pixels()[0]), int(surface.Pitch))
+	if err != nil {
+		return err
+	}
+	return surface.SaveBMP(path)
+}
+
+/* ------------------------------ main ------------------------------ */
+
+func main() {
+	flag.Parse()
+
+	var err error
+
+	gWindow, gRenderer, err = initSDL()
+	must(err)
+
+	if !gRenderer.RenderTargetSupported() {
+		panic("render targets are not supported")
+	}
+
+	loadMedia()
+	addLights()
+
+	d := dot{3 * TILE_SIZE, 6 * TILE_SIZE, 0, 0}
+
+	// The dot carries a flashlight, pointed with the mouse, and a faint glow
+	// so it can see its feet
+	flashlight := gLights.add(NewConeLight(0, 0, 260, sdl.Color{255, 250, 220, 255}, 0))
+	glow := gLights.add(NewPointLight(0, 0, 60, sdl.Color{96, 96, 96, 255}))
+
+	lighting := true
+
+	var event sdl.Event // sdl.Event is interface{}
+
+	var quit bool
+	for frame := 0; !quit; frame++ {
+		if *gFrames > 0 && frame >= *gFrames {
+			break
+		}
+
+		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				quit = true
+			case *sdl.KeyDownEvent:
+				if t.Repeat != 0 {
+					break
+				}
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_F:
+					flashlight.on = !flashlight.on
+				case sdl.SCANCODE_L:
+					lighting = !lighting
+				case sdl.SCANCODE_EQUALS:
+					setAmbient(int(gLights.ambient.R) + AMBIENT_STEP)
+				case sdl.SCANCODE_MINUS:
+					setAmbient(int(gLights.ambient.R) - AMBIENT_STEP)
+				}
+			}
+
+			d.handleEvent(event)
+		}
+
+		// Move the dot
+		d.move()
+
+		// The lights follow the dot, the flashlight turns towards the mouse
+		centerX, centerY := float64(d.x+DOT_WIDTH/2), float64(d.y+DOT_HEIGHT/2)
+		mouseX, mouseY, _ := sdl.GetMouseState()
+		flashlight.x, flashlight.y = centerX, centerY
+		flashlight.angle = math.Atan2(float64(mouseY)-centerY, float64(mouseX)-centerX) * 180 / math.Pi
+		glow.x, glow.y = centerX, centerY
+
+		gLights.update(uint32(frame) * FRAME_MS)
+
+		// Clear screen
+		gRenderer.SetDrawColor(0, 0, 0, 255)
+		gRenderer.Clear()
+
+		renderScene()
+		d.render()
+
+		if lighting {
+			gLights.render()
+		}
+
+		if *gScreenshot != "" && frame == *gFrames-1 {
+			must(saveScreenshot(*gScreenshot))
+		}
+
+		// Update screen
+		gRenderer.Present()
+
+		gWindow.SetTitle(fmt.Sprintf("%s - ambient %d (arrows, mouse, F: flashlight, L: lighting, +/-)",
+			WINDOW_TITLE, gLights.ambient.R))
+
+		sdl.Delay(16)
+	}
+
+	close()
+}
@@ -3,12 +3,12 @@ package main
 import (
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
+	"golang-sdl-tutorials/config"
 )
 
-const (
-	SCREEN_WIDTH  = 640
-	SCREEN_HEIGHT = 480
-)
+// Screen size and such, set from the defaults, a config file, the
+// environment and the command line
+var gConfig = config.DefaultWindow()
 
 var gWindow *sdl.Window
 var gRenderer *sdl.Renderer
@@ -19,14 +19,14 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create renderer
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
 
 	return window, renderer, nil
 }
@@ -66,7 +66,8 @@ func must(err error) {
 }
 
 func main() {
-	var err error
+	_, err := config.Load(&gConfig)
+	must(err)
 
 	gWindow, gRenderer, err = initSDL()
 	must(err)
@@ -89,27 +90,27 @@ func main() {
 
 		// Render red filled quad
 		fillRect := &sdl.Rect{
-			SCREEN_WIDTH / 4,
-			SCREEN_HEIGHT / 4,
-			SCREEN_WIDTH / 2,
-			SCREEN_HEIGHT / 2,
+			int32(gConfig.Width) / 4,
+			int32(gConfig.Height) / 4,
+			int32(gConfig.Width) / 2,
+			int32(gConfig.Height) / 2,
 		}
 		gRenderer.SetDrawColor(255, 0, 0, 255)
 		gRenderer.FillRect(fillRect)
 
 		// Render green outlined quad
 		outlineRect := &sdl.Rect{
-			SCREEN_WIDTH / 6,
-			SCREEN_HEIGHT / 6,
-			SCREEN_WIDTH * 2 / 3,
-			SCREEN_HEIGHT * 2 / 3,
+			int32(gConfig.Width) / 6,
+			int32(gConfig.Height) / 6,
+			int32(gConfig.Width) * 2 / 3,
+			int32(gConfig.Height) * 2 / 3,
 		}
 		gRenderer.SetDrawColor(0, 255, 0, 255)
 		gRenderer.DrawRect(outlineRect)
 
 		// Draw blue horizontal line
 		gRenderer.SetDrawColor(0, 0, 255, 255)
-		gRenderer.DrawLine(0, SCREEN_HEIGHT/2, SCREEN_WIDTH, SCREEN_HEIGHT/2)
+		gRenderer.DrawLine(0, gConfig.Height/2, gConfig.Width, gConfig.Height/2)
 
 		// Update screen
 		gRenderer.Present()
@@ -3,12 +3,17 @@ package main
 import (
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
+	"golang-sdl-tutorials/config"
 )
 
-const (
-	SCREEN_WIDTH  = 640
-	SCREEN_HEIGHT = 480
-)
+// Screen size, asset paths and such, set from the defaults here, a config
+// file, the environment and the command line
+type settings struct {
+	config.Window
+	ViewportImage string `json:"viewportImage"`
+}
+
+var gConfig = settings{config.DefaultWindow(), "assets/viewport.png"}
 
 var gWindow *sdl.Window
 var gRenderer *sdl.Renderer
@@ -21,14 +26,14 @@ func initSDL() (*sdl.Window, *sdl.Renderer, error) {
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow(gConfig.Title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		gConfig.Width, gConfig.Height, sdl.WINDOW_SHOWN)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create renderer
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
+	renderer, err := sdl.CreateRenderer(window, -1, gConfig.RendererFlags())
 
 	return window, renderer, nil
 }
@@ -51,7 +56,7 @@ func loadTexture(path string, renderer *sdl.Renderer) (*sdl.Texture, error) {
 
 func loadMedia() {
 	var err error
-	gTexture, err = loadTexture("assets/viewport.png", gRenderer)
+	gTexture, err = loadTexture(gConfig.ViewportImage, gRenderer)
 	must(err)
 }
 
@@ -72,7 +77,8 @@ func must(err error) {
 }
 
 func main() {
-	var err error
+	_, err := config.Load(&gConfig)
+	must(err)
 
 	gWindow, gRenderer, err = initSDL()
 	must(err)
@@ -93,15 +99,15 @@ func main() {
 		gRenderer.SetDrawColor(255, 255, 255, 255)
 		gRenderer.Clear()
 
-		topLeftRect := &sdl.Rect{0, 0, SCREEN_WIDTH / 2, SCREEN_HEIGHT / 2}
+		topLeftRect := &sdl.Rect{0, 0, int32(gConfig.Width) / 2, int32(gConfig.Height) / 2}
 		gRenderer.SetViewport(topLeftRect)
 		gRenderer.Copy(gTexture, nil, nil)
 
-		topRightRect := &sdl.Rect{SCREEN_WIDTH / 2, 0, SCREEN_WIDTH / 2, SCREEN_HEIGHT / 2}
+		topRightRect := &sdl.Rect{int32(gConfig.Width) / 2, 0, int32(gConfig.Width) / 2, int32(gConfig.Height) / 2}
 		gRenderer.SetViewport(topRightRect)
 		gRenderer.Copy(gTexture, nil, nil)
 
-		bottomRect := &sdl.Rect{0, SCREEN_HEIGHT / 2, SCREEN_WIDTH, SCREEN_HEIGHT / 2}
+		bottomRect := &sdl.Rect{0, int32(gConfig.Height) / 2, int32(gConfig.Width), int32(gConfig.Height) / 2}
 		gRenderer.SetViewport(bottomRect)
 		gRenderer.Copy(gTexture, nil, nil)
 